@@ -273,7 +273,7 @@ run_hardware_benchmark() {
 
     # Run the comprehensive benchmark
     echo -e "${CYAN}🚀 Starting hardware benchmark...${NC}"
-    if go run low_spec_benchmark.go; then
+    if go run low_spec_*.go bench_*.go; then
         echo -e "${GREEN}✅ Hardware benchmark completed${NC}"
     else
         echo -e "${RED}❌ Hardware benchmark failed${NC}"
@@ -301,7 +301,7 @@ run_model_comparison() {
     create_test_config "$config_file"
 
     echo -e "${CYAN}🚀 Starting model comparison tests...${NC}"
-    if go run model_comparison.go "$config_file"; then
+    if go run model_comparison.go bench_*.go "$config_file"; then
         echo -e "${GREEN}✅ Model comparison completed${NC}"
     else
         echo -e "${RED}❌ Model comparison failed${NC}"
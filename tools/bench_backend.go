@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Backend is a model runtime the benchmark tools send generations to
+type Backend interface {
+	Name() string
+	Generate(ctx context.Context, req GenerateRequest) (GenerateResponse, error)
+	Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error)
+}
+
+// ModelLister is implemented by backends that can enumerate served models
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// ModelChecker is implemented by backends with their own rules for matching
+// model names (Ollama treats "mistral" as "mistral:latest")
+type ModelChecker interface {
+	HasModel(ctx context.Context, model string) (bool, error)
+}
+
+// ModelUnloader is implemented by backends that can evict a model from
+// memory, so the next request pays the full cold-start cost
+type ModelUnloader interface {
+	Unload(ctx context.Context, model string) error
+}
+
+// Embedder is implemented by backends that serve embedding models
+type Embedder interface {
+	Embed(ctx context.Context, req EmbedRequest) (EmbedResponse, error)
+}
+
+// StartupProfile separates a model's cold-start cost from its steady state
+type StartupProfile struct {
+	LoadTime    time.Duration `json:"load_time"`    // reported by the backend for the cold run
+	ColdLatency time.Duration `json:"cold_latency"` // full response time of the cold run
+	WarmLatency time.Duration `json:"warm_latency"` // average response time of the warm runs
+}
+
+// GenerationOptions control sampling and limits. Pointer fields are unset
+// when nil, so a temperature or seed of 0 can still be requested.
+type GenerationOptions struct {
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	NumCtx      int      `json:"num_ctx,omitempty"` // context window; Ollama only
+}
+
+// withDefaults fills unset options from defaults
+func (o GenerationOptions) withDefaults(defaults GenerationOptions) GenerationOptions {
+	if o.MaxTokens == 0 {
+		o.MaxTokens = defaults.MaxTokens
+	}
+	if o.Temperature == nil {
+		o.Temperature = defaults.Temperature
+	}
+	if o.TopP == nil {
+		o.TopP = defaults.TopP
+	}
+	if o.Seed == nil {
+		o.Seed = defaults.Seed
+	}
+	if o.NumCtx == 0 {
+		o.NumCtx = defaults.NumCtx
+	}
+	return o
+}
+
+// String gives a compact form for progress output
+func (o GenerationOptions) String() string {
+	var parts []string
+	if o.MaxTokens > 0 {
+		parts = append(parts, fmt.Sprintf("max_tokens=%d", o.MaxTokens))
+	}
+	if o.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temperature=%g", *o.Temperature))
+	}
+	if o.TopP != nil {
+		parts = append(parts, fmt.Sprintf("top_p=%g", *o.TopP))
+	}
+	if o.Seed != nil {
+		parts = append(parts, fmt.Sprintf("seed=%d", *o.Seed))
+	}
+	if o.NumCtx > 0 {
+		parts = append(parts, fmt.Sprintf("num_ctx=%d", o.NumCtx))
+	}
+	if len(parts) == 0 {
+		return "runtime defaults"
+	}
+	return strings.Join(parts, " ")
+}
+
+// GenerateRequest is a single-prompt completion request
+type GenerateRequest struct {
+	Model   string            `json:"model"`
+	Prompt  string            `json:"prompt"`
+	System  string            `json:"system,omitempty"`
+	Options GenerationOptions `json:"options"`
+}
+
+// ChatMessage is one turn of a chat conversation
+type ChatMessage struct {
+	Role       string     `json:"role"` // "system", "user", "assistant", "tool"
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant turns that call tools
+	ToolName   string     `json:"tool_name,omitempty"`    // tool turns: the tool that produced Content
+	ToolCallID string     `json:"tool_call_id,omitempty"` // tool turns: the call being answered
+}
+
+// ChatRequest is a multi-message chat completion request
+type ChatRequest struct {
+	Model    string            `json:"model"`
+	Messages []ChatMessage     `json:"messages"`
+	Options  GenerationOptions `json:"options"`
+	Tools    []ToolDefinition  `json:"tools,omitempty"` // functions the model may call
+}
+
+// ToolDefinition describes a function offered to the model. The tools are
+// never run: StubResult is returned in their place.
+type ToolDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`            // JSON Schema of the arguments
+	StubResult  string          `json:"stub_result,omitempty"` // canned output handed back to the model
+}
+
+// ToolCall is a function call requested by the model
+type ToolCall struct {
+	ID            string                 `json:"id,omitempty"` // set by OpenAI-compatible servers
+	Name          string                 `json:"name"`
+	Arguments     map[string]interface{} `json:"arguments"`
+	ArgumentError string                 `json:"argument_error,omitempty"` // why arguments sent as a JSON string failed to parse
+}
+
+// functionTools renders tool definitions in the {"type": "function"} form
+// that both Ollama and OpenAI-compatible servers accept
+func functionTools(tools []ToolDefinition) []map[string]interface{} {
+	var rendered []map[string]interface{}
+	for _, tool := range tools {
+		parameters := tool.Parameters
+		if len(parameters) == 0 {
+			parameters = json.RawMessage(`{"type": "object", "properties": {}}`)
+		}
+		rendered = append(rendered, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"parameters":  parameters,
+			},
+		})
+	}
+	return rendered
+}
+
+// EmbedRequest asks for one embedding per input
+type EmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbedResponse holds the embeddings in input order
+type EmbedResponse struct {
+	Embeddings    [][]float64   `json:"embeddings"`
+	Backend       string        `json:"backend"`
+	PromptTokens  int           `json:"prompt_tokens"`
+	LoadDuration  time.Duration `json:"load_duration"`
+	TotalDuration time.Duration `json:"total_duration"`
+}
+
+// GenerateResponse holds the model output, the runtime's own accounting and
+// the client-side timing of the streamed tokens
+type GenerateResponse struct {
+	Output    string            `json:"output"`
+	ToolCalls []ToolCall        `json:"tool_calls,omitempty"`
+	Backend   string            `json:"backend"` // Name() of the backend that served the request
+	Metrics   GenerationMetrics `json:"metrics"`
+	Timing    StreamTiming      `json:"timing"`
+}
+
+// GenerationMetrics are the token counts and timings reported by the backend.
+// Field names follow Ollama's API so results can be cross-checked with its logs.
+type GenerationMetrics struct {
+	PromptEvalCount    int           `json:"prompt_eval_count"`
+	EvalCount          int           `json:"eval_count"`
+	LoadDuration       time.Duration `json:"load_duration"`
+	PromptEvalDuration time.Duration `json:"prompt_eval_duration"`
+	EvalDuration       time.Duration `json:"eval_duration"`
+	TotalDuration      time.Duration `json:"total_duration"`
+	EvalCountEstimated bool          `json:"eval_count_estimated,omitempty"` // EvalCount is the streamed chunk count; the server reported no token counts
+}
+
+// DecodeRate returns measured output tokens per second, or 0 if unknown
+func (m GenerationMetrics) DecodeRate() float64 {
+	if m.EvalCount == 0 || m.EvalDuration <= 0 {
+		return 0
+	}
+	return float64(m.EvalCount) / m.EvalDuration.Seconds()
+}
+
+// PrefillRate returns measured prompt tokens per second, or 0 if unknown
+func (m GenerationMetrics) PrefillRate() float64 {
+	if m.PromptEvalCount == 0 || m.PromptEvalDuration <= 0 {
+		return 0
+	}
+	return float64(m.PromptEvalCount) / m.PromptEvalDuration.Seconds()
+}
+
+// BackendConfig selects the runtime a model is served from
+type BackendConfig struct {
+	Type      string `json:"type"`                  // "ollama" (default) or "openai"
+	BaseURL   string `json:"base_url,omitempty"`    // e.g. http://localhost:8080 for llama.cpp
+	APIKeyEnv string `json:"api_key_env,omitempty"` // env var holding the API key, if any
+	ModelID   string `json:"model_id,omitempty"`    // model name on that server (defaults to the config name)
+}
+
+// newBackend builds the backend described by cfg
+func newBackend(cfg BackendConfig) (Backend, error) {
+	switch cfg.Type {
+	case "", "ollama":
+		return NewOllamaBackend(cfg.BaseURL), nil
+	case "openai":
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("openai backend needs a base_url")
+		}
+		return NewOpenAIBackend(cfg.BaseURL, apiKeyFromEnv(cfg.APIKeyEnv)), nil
+	default:
+		return nil, fmt.Errorf("unknown backend type %q", cfg.Type)
+	}
+}
+
+// RoutedBackend sends each model to its configured backend, falling back to
+// Default for models without an entry. This lets one experiment compare the
+// same test cases across runtimes.
+type RoutedBackend struct {
+	Default Backend
+	routes  map[string]backendRoute
+}
+
+type backendRoute struct {
+	backend Backend
+	modelID string
+}
+
+// newRoutedBackend builds routes for the per-model configs on top of the
+// default Ollama backend
+func newRoutedBackend(configs map[string]BackendConfig) (*RoutedBackend, error) {
+	router := &RoutedBackend{
+		Default: newDefaultBackend(),
+		routes:  make(map[string]backendRoute),
+	}
+
+	for model, cfg := range configs {
+		backend, err := newBackend(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("backend for %s: %w", model, err)
+		}
+		modelID := cfg.ModelID
+		if modelID == "" {
+			modelID = model
+		}
+		router.routes[model] = backendRoute{backend: backend, modelID: modelID}
+	}
+
+	return router, nil
+}
+
+// Resolve returns the backend serving model and the name to send it
+func (r *RoutedBackend) Resolve(model string) (Backend, string) {
+	if route, ok := r.routes[model]; ok {
+		return route.backend, route.modelID
+	}
+	return r.Default, model
+}
+
+func (r *RoutedBackend) Name() string {
+	return "routed"
+}
+
+func (r *RoutedBackend) Generate(ctx context.Context, req GenerateRequest) (GenerateResponse, error) {
+	backend, modelID := r.Resolve(req.Model)
+	req.Model = modelID
+	resp, err := backend.Generate(ctx, req)
+	resp.Backend = backend.Name() // also reported for failed requests
+	return resp, err
+}
+
+func (r *RoutedBackend) Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error) {
+	backend, modelID := r.Resolve(req.Model)
+	req.Model = modelID
+	resp, err := backend.Chat(ctx, req)
+	resp.Backend = backend.Name()
+	return resp, err
+}
+
+// Embed routes like Generate; the serving backend must implement Embedder
+func (r *RoutedBackend) Embed(ctx context.Context, req EmbedRequest) (EmbedResponse, error) {
+	backend, modelID := r.Resolve(req.Model)
+	req.Model = modelID
+	resp, err := embed(ctx, backend, req)
+	resp.Backend = backend.Name()
+	return resp, err
+}
+
+// embed calls the backend's Embed, failing for backends without embedding support
+func embed(ctx context.Context, backend Backend, req EmbedRequest) (EmbedResponse, error) {
+	embedder, ok := backend.(Embedder)
+	if !ok {
+		return EmbedResponse{Backend: backend.Name()}, fmt.Errorf("%s backend does not support embeddings", backend.Name())
+	}
+	return embedder.Embed(ctx, req)
+}
+
+// newDefaultBackend returns the Ollama backend at OLLAMA_HOST (or localhost)
+func newDefaultBackend() Backend {
+	return NewOllamaBackend(os.Getenv("OLLAMA_HOST"))
+}
+
+// modelAvailable checks model against the backend that would serve it.
+// Backends that can't enumerate models are assumed to have it; the run
+// itself will report the error if not.
+func modelAvailable(ctx context.Context, backend Backend, model string) bool {
+	switch b := backend.(type) {
+	case *RoutedBackend:
+		leaf, modelID := b.Resolve(model)
+		return modelAvailable(ctx, leaf, modelID)
+	case *RecordingBackend:
+		return modelAvailable(ctx, b.Inner, model)
+	}
+
+	if checker, ok := backend.(ModelChecker); ok {
+		available, err := checker.HasModel(ctx, model)
+		return available && err == nil
+	}
+
+	lister, ok := backend.(ModelLister)
+	if !ok {
+		return true
+	}
+
+	ids, err := lister.ListModels(ctx)
+	if err != nil {
+		return false
+	}
+	for _, id := range ids {
+		if id == model {
+			return true
+		}
+	}
+	return false
+}
+
+// listBackendModels returns the models the backend reports, looking through
+// routing (to the default backend) and recording wrappers
+func listBackendModels(backend Backend) []string {
+	switch b := backend.(type) {
+	case *RoutedBackend:
+		return listBackendModels(b.Default)
+	case *RecordingBackend:
+		return listBackendModels(b.Inner)
+	}
+
+	lister, ok := backend.(ModelLister)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil
+	}
+	return models
+}
+
+// unloadModel evicts model from the backend that serves it. supported is
+// false when that backend can't unload, so no true cold run is possible.
+func unloadModel(backend Backend, model string) (supported bool, err error) {
+	switch b := backend.(type) {
+	case *RoutedBackend:
+		leaf, modelID := b.Resolve(model)
+		return unloadModel(leaf, modelID)
+	case *RecordingBackend:
+		return unloadModel(b.Inner, model)
+	}
+
+	unloader, ok := backend.(ModelUnloader)
+	if !ok {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return true, unloader.Unload(ctx, model)
+}
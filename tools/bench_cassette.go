@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cassette is a recorded sequence of backend exchanges
+type Cassette struct {
+	RecordedAt   time.Time     `json:"recorded_at"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and what the live backend answered
+type Interaction struct {
+	Kind      string           `json:"kind"` // "generate", "chat" or "embed"
+	Request   json.RawMessage  `json:"request"`
+	Response  GenerateResponse `json:"response"`
+	Embedding *EmbedResponse   `json:"embedding,omitempty"` // set for "embed"
+	Error     string           `json:"error,omitempty"`
+	Elapsed   time.Duration    `json:"elapsed"` // wall time of the live request
+}
+
+// RecordingBackend passes requests through to a live backend and records
+// every exchange for later replay
+type RecordingBackend struct {
+	Inner Backend
+	Path  string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecordingBackend records exchanges with inner; call Save to write them
+func NewRecordingBackend(inner Backend, path string) *RecordingBackend {
+	return &RecordingBackend{
+		Inner:    inner,
+		Path:     path,
+		cassette: Cassette{RecordedAt: time.Now()},
+	}
+}
+
+func (b *RecordingBackend) Name() string {
+	return b.Inner.Name()
+}
+
+func (b *RecordingBackend) Generate(ctx context.Context, req GenerateRequest) (GenerateResponse, error) {
+	start := time.Now()
+	resp, err := b.Inner.Generate(ctx, req)
+	b.record("generate", req, Interaction{Response: resp}, err, time.Since(start))
+	return resp, err
+}
+
+func (b *RecordingBackend) Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error) {
+	start := time.Now()
+	resp, err := b.Inner.Chat(ctx, req)
+	b.record("chat", req, Interaction{Response: resp}, err, time.Since(start))
+	return resp, err
+}
+
+func (b *RecordingBackend) Embed(ctx context.Context, req EmbedRequest) (EmbedResponse, error) {
+	start := time.Now()
+	resp, err := embed(ctx, b.Inner, req)
+	b.record("embed", req, Interaction{Embedding: &resp}, err, time.Since(start))
+	return resp, err
+}
+
+// record completes interaction, which carries the response, and appends it
+func (b *RecordingBackend) record(kind string, req interface{}, interaction Interaction, err error, elapsed time.Duration) {
+	data, _ := json.Marshal(req)
+	interaction.Kind = kind
+	interaction.Request = data
+	interaction.Elapsed = elapsed
+	if err != nil {
+		interaction.Error = err.Error()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cassette.Interactions = append(b.cassette.Interactions, interaction)
+}
+
+// Save writes the cassette to Path
+func (b *RecordingBackend) Save() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(b.Path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(b.Path, data, 0644)
+}
+
+// ReplayBackend answers requests from a cassette. Identical requests are
+// answered in recorded order, and each reply waits as long as the live one
+// took so wall-clock measurements are reproduced.
+type ReplayBackend struct {
+	mu      sync.Mutex
+	pending map[string][]Interaction
+	models  []string
+}
+
+// LoadReplayBackend reads a cassette written by RecordingBackend
+func LoadReplayBackend(path string) (*ReplayBackend, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+
+	b := &ReplayBackend{pending: make(map[string][]Interaction)}
+	seen := make(map[string]bool)
+	for _, interaction := range cassette.Interactions {
+		// The cassette is indented on disk; keys use the compact form
+		var request bytes.Buffer
+		if err := json.Compact(&request, interaction.Request); err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", path, err)
+		}
+		key := interaction.Kind + " " + request.String()
+		b.pending[key] = append(b.pending[key], interaction)
+
+		var req struct {
+			Model string `json:"model"`
+		}
+		if json.Unmarshal(interaction.Request, &req) == nil && !seen[req.Model] {
+			seen[req.Model] = true
+			b.models = append(b.models, req.Model)
+		}
+	}
+
+	return b, nil
+}
+
+func (b *ReplayBackend) Name() string {
+	return "replay"
+}
+
+func (b *ReplayBackend) Generate(ctx context.Context, req GenerateRequest) (GenerateResponse, error) {
+	interaction, err := b.replay(ctx, "generate", req)
+	if err != nil {
+		return GenerateResponse{Backend: b.Name()}, err
+	}
+	return interaction.Response, interactionError(interaction)
+}
+
+func (b *ReplayBackend) Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error) {
+	interaction, err := b.replay(ctx, "chat", req)
+	if err != nil {
+		return GenerateResponse{Backend: b.Name()}, err
+	}
+	return interaction.Response, interactionError(interaction)
+}
+
+func (b *ReplayBackend) Embed(ctx context.Context, req EmbedRequest) (EmbedResponse, error) {
+	interaction, err := b.replay(ctx, "embed", req)
+	if err != nil || interaction.Embedding == nil {
+		return EmbedResponse{Backend: b.Name()}, err
+	}
+	return *interaction.Embedding, interactionError(interaction)
+}
+
+// Unload is a no-op: recorded cold runs already include their load time
+func (b *ReplayBackend) Unload(ctx context.Context, model string) error {
+	return nil
+}
+
+// ListModels reports the models that appear in the cassette
+func (b *ReplayBackend) ListModels(ctx context.Context) ([]string, error) {
+	return b.models, nil
+}
+
+// replay takes the next recorded interaction for the request, after waiting
+// as long as the live request took
+func (b *ReplayBackend) replay(ctx context.Context, kind string, req interface{}) (Interaction, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return Interaction{}, err
+	}
+	key := kind + " " + string(data)
+
+	b.mu.Lock()
+	queue := b.pending[key]
+	if len(queue) == 0 {
+		b.mu.Unlock()
+		return Interaction{}, fmt.Errorf("replay: no recorded %s for %s", kind, data)
+	}
+	interaction := queue[0]
+	b.pending[key] = queue[1:]
+	b.mu.Unlock()
+
+	select {
+	case <-time.After(interaction.Elapsed):
+	case <-ctx.Done():
+		return Interaction{}, ctx.Err()
+	}
+	return interaction, nil
+}
+
+// interactionError is the recorded error, if the live request failed
+func interactionError(interaction Interaction) error {
+	if interaction.Error != "" {
+		return errors.New(interaction.Error)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordingReplaysIdentically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "run.json")
+	recorder := NewRecordingBackend(&MockBackend{Script: latencyScript}, path)
+	ctx := context.Background()
+
+	generate := GenerateRequest{Model: "tiny", Prompt: "the weather now"}
+	chat := ChatRequest{Model: "tiny", Messages: []ChatMessage{{Role: "user", Content: "weather?"}}}
+	embed := EmbedRequest{Model: "tiny", Input: []string{"sunny weather"}}
+	broken := GenerateRequest{Model: "tiny", Prompt: "broken"}
+
+	// The same request twice: a cold and a warm answer
+	var live []GenerateResponse
+	for _, req := range []GenerateRequest{generate, generate} {
+		resp, err := recorder.Generate(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		live = append(live, resp)
+	}
+	liveChat, _ := recorder.Chat(ctx, chat)
+	liveEmbed, _ := recorder.Embed(ctx, embed)
+	_, liveErr := recorder.Generate(ctx, broken)
+	if liveErr == nil {
+		t.Fatal("scripted error wasn't returned")
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := LoadReplayBackend(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Identical requests come back in recorded order
+	for i, want := range live {
+		got, err := replay.Generate(ctx, generate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("replay %d = %+v\nwant %+v", i, got, want)
+		}
+	}
+	if got, err := replay.Chat(ctx, chat); err != nil || !reflect.DeepEqual(got, liveChat) {
+		t.Errorf("chat = %+v, %v\nwant %+v", got, err, liveChat)
+	}
+	if got, err := replay.Embed(ctx, embed); err != nil || !reflect.DeepEqual(got, liveEmbed) {
+		t.Errorf("embed = %+v, %v\nwant %+v", got, err, liveEmbed)
+	}
+	if _, err := replay.Generate(ctx, broken); err == nil || err.Error() != liveErr.Error() {
+		t.Errorf("replayed error = %v, want %v", err, liveErr)
+	}
+
+	if models, _ := replay.ListModels(ctx); !reflect.DeepEqual(models, []string{"tiny"}) {
+		t.Errorf("models = %v", models)
+	}
+}
+
+func TestReplayMiss(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	recorder := NewRecordingBackend(&MockBackend{Script: latencyScript}, path)
+	ctx := context.Background()
+	recorder.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather"})
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := LoadReplayBackend(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Any change to the request misses the cassette
+	_, err = replay.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather", Options: GenerationOptions{MaxTokens: 5}})
+	if err == nil || !strings.Contains(err.Error(), "no recorded generate") {
+		t.Errorf("changed request error = %v", err)
+	}
+	if _, err := replay.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather"}); err != nil {
+		t.Fatal(err)
+	}
+	// Each recording answers once
+	if _, err := replay.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather"}); err == nil {
+		t.Error("recording replayed twice")
+	}
+
+	if _, err := LoadReplayBackend(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("missing cassette should fail to load")
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ConversationTurn is a follow-up user message in a multi-turn test case.
+// The assistant turns in between are generated live, so every turn builds on
+// what the model actually said.
+type ConversationTurn struct {
+	User   string   `json:"user"`
+	Expect []string `json:"expect,omitempty"` // facts from earlier turns the reply must still contain
+}
+
+// TurnResult measures one exchange of a conversation
+type TurnResult struct {
+	Turn             int           `json:"turn"` // 1 is the opening prompt
+	Prompt           string        `json:"prompt"`
+	Output           string        `json:"output"`
+	Success          bool          `json:"success"`
+	Error            string        `json:"error,omitempty"`
+	ResponseTime     time.Duration `json:"response_time"`
+	TimeToFirstToken time.Duration `json:"time_to_first_token"`
+	TokensPerSecond  float64       `json:"tokens_per_second"`
+	PromptTokens     int           `json:"prompt_tokens"` // grows with the history
+	QualityScore     float64       `json:"quality_score"`
+	FactsExpected    int           `json:"facts_expected"`
+	FactsRecalled    int           `json:"facts_recalled"`
+	MissingFacts     []string      `json:"missing_facts,omitempty"`
+}
+
+// runConversation sends prompt and then each follow-up turn as a chat,
+// appending the model's replies to the history. score rates a single reply
+// and may be nil. The last response is returned for the aggregate metrics;
+// on error it belongs to the failed turn.
+func runConversation(ctx context.Context, backend Backend, model, prompt string, turns []ConversationTurn, options GenerationOptions, score func(output string) float64) ([]TurnResult, GenerateResponse, error) {
+	var results []TurnResult
+	var messages []ChatMessage
+	var resp GenerateResponse
+
+	exchanges := append([]ConversationTurn{{User: prompt}}, turns...)
+	for i, exchange := range exchanges {
+		messages = append(messages, ChatMessage{Role: "user", Content: exchange.User})
+		turn := TurnResult{Turn: i + 1, Prompt: exchange.User, FactsExpected: len(exchange.Expect)}
+
+		start := time.Now()
+		var err error
+		resp, err = backend.Chat(ctx, ChatRequest{Model: model, Messages: messages, Options: options})
+		turn.ResponseTime = time.Since(start)
+		if err != nil {
+			turn.Error = err.Error()
+			results = append(results, turn)
+			return results, resp, err
+		}
+
+		turn.Success = true
+		turn.Output = resp.Output
+		turn.TimeToFirstToken = resp.Timing.TimeToFirstToken
+		turn.TokensPerSecond = resp.Metrics.DecodeRate()
+		turn.PromptTokens = resp.Metrics.PromptEvalCount
+		turn.FactsRecalled, turn.MissingFacts = recalledFacts(resp.Output, exchange.Expect)
+		if score != nil {
+			turn.QualityScore = score(resp.Output)
+		}
+		results = append(results, turn)
+
+		messages = append(messages, ChatMessage{Role: "assistant", Content: resp.Output})
+	}
+
+	return results, resp, nil
+}
+
+// recalledFacts counts the facts output still mentions, ignoring case
+func recalledFacts(output string, facts []string) (int, []string) {
+	lower := strings.ToLower(output)
+	recalled := 0
+	var missing []string
+	for _, fact := range facts {
+		if strings.Contains(lower, strings.ToLower(fact)) {
+			recalled++
+		} else {
+			missing = append(missing, fact)
+		}
+	}
+	return recalled, missing
+}
+
+// factRetention is the share of expected facts recalled across all turns,
+// or 1 when no turn expected any
+func factRetention(turns []TurnResult) float64 {
+	var expected, recalled int
+	for _, turn := range turns {
+		expected += turn.FactsExpected
+		recalled += turn.FactsRecalled
+	}
+	if expected == 0 {
+		return 1
+	}
+	return float64(recalled) / float64(expected)
+}
+
+// averageTurnLatency averages the successful response times at each turn
+// position, showing how latency grows with the history
+func averageTurnLatency(conversations [][]TurnResult) []time.Duration {
+	var totals []time.Duration
+	var counts []int
+	for _, turns := range conversations {
+		for i, turn := range turns {
+			if i == len(totals) {
+				totals = append(totals, 0)
+				counts = append(counts, 0)
+			}
+			if turn.Success {
+				totals[i] += turn.ResponseTime
+				counts[i]++
+			}
+		}
+	}
+
+	for i := range totals {
+		if counts[i] > 0 {
+			totals[i] /= time.Duration(counts[i])
+		}
+	}
+	return totals
+}
+
+// formatTurnLatency renders per-turn latencies as "1.2s → 1.8s → 2.5s"
+func formatTurnLatency(latencies []time.Duration) string {
+	var parts []string
+	for _, latency := range latencies {
+		parts = append(parts, latency.Round(10*time.Millisecond).String())
+	}
+	return strings.Join(parts, " → ")
+}
+
+// turnLatencies lists the response time of each turn of one conversation
+func turnLatencies(turns []TurnResult) []time.Duration {
+	var latencies []time.Duration
+	for _, turn := range turns {
+		latencies = append(latencies, turn.ResponseTime)
+	}
+	return latencies
+}
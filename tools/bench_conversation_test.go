@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chatLog records the history sent with every chat
+type chatLog struct {
+	*MockBackend
+	requests [][]ChatMessage
+}
+
+func (b *chatLog) Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error) {
+	b.requests = append(b.requests, append([]ChatMessage(nil), req.Messages...))
+	return b.MockBackend.Chat(ctx, req)
+}
+
+var conversationScript = MockScript{Responses: []MockResponse{
+	{Match: "Draft", Output: "Added Redis caching, queries down 60%.", PromptTokens: 4, TokensPerSecond: 1000},
+	{Match: "shorter", Output: "redis cache cut queries 60%.", PromptTokens: 4, TokensPerSecond: 1000},
+	{Match: "tweet", Output: "Shipped a cache!", PromptTokens: 4, TokensPerSecond: 1000},
+	{Match: "thread", Error: "out of memory"},
+}}
+
+func TestRunConversation(t *testing.T) {
+	backend := &chatLog{MockBackend: &MockBackend{Script: conversationScript}}
+	turns := []ConversationTurn{
+		{User: "Make it shorter", Expect: []string{"Redis", "60%"}},
+		{User: "Now a tweet", Expect: []string{"Redis", "60%"}},
+	}
+	words := func(output string) float64 { return float64(len(strings.Fields(output))) }
+
+	results, resp, err := runConversation(context.Background(), backend, "mock", "Draft a devlog entry", turns, GenerationOptions{}, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || resp.Output != "Shipped a cache!" {
+		t.Fatalf("%d turns, last output %q", len(results), resp.Output)
+	}
+
+	// Each chat carries every earlier exchange, with the model's own replies
+	var sent []int
+	for _, messages := range backend.requests {
+		sent = append(sent, len(messages))
+	}
+	if !reflect.DeepEqual(sent, []int{1, 3, 5}) {
+		t.Fatalf("messages sent = %v", sent)
+	}
+	last := backend.requests[2]
+	for i, role := range []string{"user", "assistant", "user", "assistant", "user"} {
+		if last[i].Role != role {
+			t.Errorf("message %d is from %q, want %q", i, last[i].Role, role)
+		}
+	}
+	if last[1].Content != results[0].Output || last[3].Content != results[1].Output || last[4].Content != "Now a tweet" {
+		t.Errorf("history = %+v", last)
+	}
+
+	tests := []struct {
+		prompt             string
+		expected, recalled int
+		missing            []string
+		quality            float64
+	}{
+		{"Draft a devlog entry", 0, 0, nil, 6},
+		// Facts match ignoring case
+		{"Make it shorter", 2, 2, nil, 5},
+		{"Now a tweet", 2, 0, []string{"Redis", "60%"}, 3},
+	}
+	for i, tt := range tests {
+		turn := results[i]
+		if turn.Turn != i+1 || turn.Prompt != tt.prompt || !turn.Success {
+			t.Errorf("turn %d = %+v", i+1, turn)
+		}
+		if turn.FactsExpected != tt.expected || turn.FactsRecalled != tt.recalled || !reflect.DeepEqual(turn.MissingFacts, tt.missing) {
+			t.Errorf("turn %d facts: %d of %d, missing %q", i+1, turn.FactsRecalled, turn.FactsExpected, turn.MissingFacts)
+		}
+		if turn.QualityScore != tt.quality {
+			t.Errorf("turn %d quality = %v, want %v", i+1, turn.QualityScore, tt.quality)
+		}
+		// The history is prefilled again on every turn
+		if i > 0 && turn.PromptTokens <= results[i-1].PromptTokens {
+			t.Errorf("turn %d prefilled %d tokens after %d", i+1, turn.PromptTokens, results[i-1].PromptTokens)
+		}
+	}
+
+	if retention := factRetention(results); retention != 0.5 {
+		t.Errorf("fact retention = %v", retention)
+	}
+}
+
+func TestRunConversationStopsOnError(t *testing.T) {
+	turns := []ConversationTurn{
+		{User: "Now a thread", Expect: []string{"Redis"}},
+		{User: "Now a tweet"},
+	}
+
+	results, _, err := runConversation(context.Background(), &MockBackend{Script: conversationScript}, "mock", "Draft a devlog entry", turns, GenerationOptions{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "out of memory") {
+		t.Fatalf("error = %v", err)
+	}
+	// The failed turn is kept; the ones after it never ran
+	if len(results) != 2 || !results[0].Success || results[1].Success || results[1].Error == "" {
+		t.Fatalf("turns = %+v", results)
+	}
+	// Without a scorer replies aren't rated
+	if results[0].QualityScore != 0 {
+		t.Errorf("quality = %v", results[0].QualityScore)
+	}
+	if retention := factRetention(results); retention != 0 {
+		t.Errorf("fact retention = %v", retention)
+	}
+	if retention := factRetention(results[:1]); retention != 1 {
+		t.Errorf("fact retention with nothing expected = %v", retention)
+	}
+}
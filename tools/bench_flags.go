@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BackendOptions are the command-line switches every benchmark tool accepts
+type BackendOptions struct {
+	Mock        bool   // --mock[=script.json]: scripted responses, no Ollama needed
+	MockScript  string // empty means the built-in script
+	Record      string // --record=cassette.json: capture live exchanges
+	Replay      string // --replay=cassette.json: answer from a recorded cassette
+	PullMissing bool   // --pull-missing: download configured models that aren't installed
+}
+
+// parseBackendOptions strips the backend switches from args and returns the rest
+func parseBackendOptions(args []string) (BackendOptions, []string, error) {
+	var opts BackendOptions
+	var rest []string
+
+	for _, arg := range args {
+		switch {
+		case arg == "--mock":
+			opts.Mock = true
+		case strings.HasPrefix(arg, "--mock="):
+			opts.Mock = true
+			opts.MockScript = strings.TrimPrefix(arg, "--mock=")
+		case strings.HasPrefix(arg, "--record="):
+			opts.Record = strings.TrimPrefix(arg, "--record=")
+		case strings.HasPrefix(arg, "--replay="):
+			opts.Replay = strings.TrimPrefix(arg, "--replay=")
+		case arg == "--pull-missing":
+			opts.PullMissing = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	modes := 0
+	for _, set := range []bool{opts.Mock, opts.Record != "", opts.Replay != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return opts, rest, fmt.Errorf("--mock, --record and --replay are mutually exclusive")
+	}
+
+	return opts, rest, nil
+}
+
+// apply returns the backend a tool should use in place of live, plus a
+// finish func to call once the run is over (it saves recorded cassettes)
+func (o BackendOptions) apply(live Backend) (Backend, func() error, error) {
+	noop := func() error { return nil }
+
+	switch {
+	case o.Mock:
+		script, err := loadMockScript(o.MockScript)
+		if err != nil {
+			return nil, noop, fmt.Errorf("mock script: %w", err)
+		}
+		return &MockBackend{Script: script}, noop, nil
+	case o.Replay != "":
+		replay, err := LoadReplayBackend(o.Replay)
+		if err != nil {
+			return nil, noop, err
+		}
+		return replay, noop, nil
+	case o.Record != "":
+		recorder := NewRecordingBackend(live, o.Record)
+		return recorder, recorder.Save, nil
+	}
+
+	return live, noop, nil
+}
+
+// offline reports whether no live runtime is involved
+func (o BackendOptions) offline() bool {
+	return o.Mock || o.Replay != ""
+}
+
+// backendOptionsHelp is appended to each tool's usage text
+const backendOptionsHelp = `  --mock[=script.json]   Use scripted responses instead of a live runtime
+  --record=cassette.json Record live backend exchanges to a cassette
+  --replay=cassette.json Replay a recorded cassette instead of a live runtime
+  --pull-missing         Pull configured models that aren't installed`
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ModelInfo is what the runtime reports about an installed model
+type ModelInfo struct {
+	Name              string   `json:"name"`
+	Digest            string   `json:"digest"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families,omitempty"`
+	Format            string   `json:"format"`
+	ParameterSize     string   `json:"parameter_size"` // as reported, e.g. "7.2B"
+	ParameterCount    int64    `json:"parameter_count"`
+	QuantizationLevel string   `json:"quantization_level"` // e.g. "Q4_K_M", "Q8_0", "F16"
+	ContextLength     int      `json:"context_length"`
+	SizeBytes         int64    `json:"size_bytes"`             // on disk
+	Capabilities      []string `json:"capabilities,omitempty"` // "completion", "embedding", ...; empty if not reported
+}
+
+// SizeLabel gives the parameter count in the "7b" form used in reports
+func (m ModelInfo) SizeLabel() string {
+	switch {
+	case m.ParameterCount >= 1e9:
+		return fmt.Sprintf("%.0fb", float64(m.ParameterCount)/1e9)
+	case m.ParameterCount > 0:
+		return fmt.Sprintf("%.0fm", float64(m.ParameterCount)/1e6)
+	}
+	return "unknown"
+}
+
+// SizeMB is the on-disk size in megabytes
+func (m ModelInfo) SizeMB() int64 {
+	return m.SizeBytes / 1024 / 1024
+}
+
+// ModelInventory indexes installed models by their canonical name
+type ModelInventory struct {
+	Models []ModelInfo `json:"models"`
+	byName map[string]ModelInfo
+}
+
+// InventoryProvider is implemented by backends that report model metadata
+type InventoryProvider interface {
+	Inventory(ctx context.Context) (*ModelInventory, error)
+}
+
+func newModelInventory(models []ModelInfo) *ModelInventory {
+	inv := &ModelInventory{byName: make(map[string]ModelInfo)}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	for _, model := range models {
+		inv.Models = append(inv.Models, model)
+		inv.byName[canonicalModelName(model.Name)] = model
+	}
+	return inv
+}
+
+// Lookup finds a model by exact name ("mistral" and "mistral:latest" are the same model)
+func (inv *ModelInventory) Lookup(name string) (ModelInfo, bool) {
+	if inv == nil {
+		return ModelInfo{}, false
+	}
+	info, ok := inv.byName[canonicalModelName(name)]
+	return info, ok
+}
+
+// Names lists the installed models
+func (inv *ModelInventory) Names() []string {
+	if inv == nil {
+		return nil
+	}
+	var names []string
+	for _, model := range inv.Models {
+		names = append(names, model.Name)
+	}
+	return names
+}
+
+// isEmbeddingModel reports whether a model serves embeddings rather than
+// completions. Servers that don't report capabilities fall back to the name
+// ("nomic-embed-text", "mxbai-embed-large").
+func isEmbeddingModel(name string, info ModelInfo) bool {
+	if len(info.Capabilities) > 0 {
+		for _, capability := range info.Capabilities {
+			if capability == "embedding" {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(strings.ToLower(name), "embed")
+}
+
+// withoutEmbeddingModels drops the models that can't generate text
+func withoutEmbeddingModels(models []string, inventory *ModelInventory) []string {
+	var generation []string
+	for _, model := range models {
+		info, _ := inventory.Lookup(model)
+		if !isEmbeddingModel(model, info) {
+			generation = append(generation, model)
+		}
+	}
+	return generation
+}
+
+// canonicalModelName adds the implicit ":latest" tag Ollama assumes
+func canonicalModelName(name string) string {
+	name = strings.TrimSpace(name)
+	if name != "" && !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+	return name
+}
+
+// parseParameterSize turns Ollama's "7.2B" / "270M" labels into a count
+func parseParameterSize(size string) int64 {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(size, "B"):
+		multiplier = 1e9
+	case strings.HasSuffix(size, "M"):
+		multiplier = 1e6
+	case strings.HasSuffix(size, "K"):
+		multiplier = 1e3
+	}
+	value, err := strconv.ParseFloat(strings.TrimRight(size, "BMK"), 64)
+	if err != nil {
+		return 0
+	}
+	return int64(value * multiplier)
+}
+
+// modelInventory fetches metadata from the backend that serves local models,
+// looking through routing and recording wrappers. Returns nil when unavailable.
+func modelInventory(backend Backend) *ModelInventory {
+	switch b := backend.(type) {
+	case *RoutedBackend:
+		return modelInventory(b.Default)
+	case *RecordingBackend:
+		return modelInventory(b.Inner)
+	}
+
+	provider, ok := backend.(InventoryProvider)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	inv, err := provider.Inventory(ctx)
+	if err != nil {
+		fmt.Printf("⚠️  Could not read model inventory: %v\n", err)
+		return nil
+	}
+	return inv
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalModelName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"mistral", "mistral:latest"},
+		{" mistral ", "mistral:latest"},
+		{"mistral:latest", "mistral:latest"},
+		{"mistral:7b-instruct-q4_0", "mistral:7b-instruct-q4_0"},
+		{"dolphin-mistral", "dolphin-mistral:latest"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := canonicalModelName(tt.name); got != tt.want {
+			t.Errorf("canonicalModelName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// tagsServer stubs /api/tags listing the given model names
+func tagsServer(t *testing.T, names ...string) *OllamaBackend {
+	t.Helper()
+	var models []map[string]string
+	for _, name := range names {
+		models = append(models, map[string]string{"name": name})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"models": models})
+	}))
+	t.Cleanup(srv.Close)
+	return NewOllamaBackend(srv.URL)
+}
+
+func TestOllamaHasModel(t *testing.T) {
+	both := tagsServer(t, "dolphin-mistral:latest", "mistral:latest")
+	// Only the fine-tune, whose name contains "mistral"
+	dolphin := tagsServer(t, "dolphin-mistral:latest")
+
+	tests := []struct {
+		name    string
+		backend *OllamaBackend
+		model   string
+		want    bool
+	}{
+		{"untagged", both, "mistral", true},
+		{"latest", both, "mistral:latest", true},
+		{"other tag", both, "mistral:7b", false},
+		{"fine-tune untagged", both, "dolphin-mistral", true},
+		{"substring of an installed model", dolphin, "mistral", false},
+		{"substring with latest", dolphin, "mistral:latest", false},
+		{"prefix of an installed model", dolphin, "dolphin", false},
+		{"fine-tune latest", dolphin, "dolphin-mistral:latest", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installed, err := tt.backend.HasModel(context.Background(), tt.model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if installed != tt.want {
+				t.Errorf("HasModel(%q) = %v, want %v", tt.model, installed, tt.want)
+			}
+			if available := modelAvailable(context.Background(), tt.backend, tt.model); available != tt.want {
+				t.Errorf("modelAvailable(%q) = %v", tt.model, available)
+			}
+		})
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	if _, err := NewOllamaBackend(srv.URL).HasModel(context.Background(), "mistral"); err == nil {
+		t.Error("an unreachable server reported no error")
+	}
+}
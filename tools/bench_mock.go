@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockScript scripts the responses of a MockBackend
+type MockScript struct {
+	Models    []string       `json:"models"`
+	Responses []MockResponse `json:"responses"`
+	Default   MockResponse   `json:"default"`
+	Slots     int            `json:"slots,omitempty"`     // requests decoded at full speed at once; 0 means unlimited
+	Inventory []ModelInfo    `json:"inventory,omitempty"` // metadata reported for the scripted models
+}
+
+// MockResponse is one scripted reply. The first response whose model and
+// match both fit the request is used; empty fields match anything.
+type MockResponse struct {
+	Model            string  `json:"model,omitempty"`
+	Match            string  `json:"match,omitempty"` // substring of the prompt (or last user message)
+	Output           string  `json:"output"`
+	Error            string  `json:"error,omitempty"`
+	PromptTokens     int     `json:"prompt_tokens,omitempty"`
+	OutputTokens     int     `json:"output_tokens,omitempty"`          // defaults to the word count of output
+	LoadMS           int     `json:"load_ms,omitempty"`                // paid by the first request after start or unload
+	TimeToFirstToken int     `json:"time_to_first_token_ms,omitempty"` // once loaded
+	TokensPerSecond  float64 `json:"tokens_per_second,omitempty"`
+
+	// ToolCalls answer chat requests that offer tools; once the tool results
+	// are in, Output is the reply
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// MockBackend serves scripted responses with simulated latency and token
+// counts, so the tools can run without Ollama or any model installed
+type MockBackend struct {
+	Script MockScript
+
+	mu       sync.Mutex
+	loaded   map[string]bool
+	inFlight int
+}
+
+// defaultMockScript is used by --mock without a script file
+func defaultMockScript() MockScript {
+	return MockScript{
+		Models: []string{"mock-fast:3b", "mock-quality:7b", "mock-embed:small"},
+		Responses: []MockResponse{
+			// Structured output: the quality model is valid first time, the
+			// fast one needs a retry
+			{
+				Model:            "mock-quality:7b",
+				Match:            "JSON Schema",
+				Output:           `{"tags": ["session", "cache", "race-condition"], "component": "session cache", "impact": "high", "type": "bugfix"}`,
+				PromptTokens:     160,
+				LoadMS:           2500,
+				TimeToFirstToken: 400,
+				TokensPerSecond:  40,
+			},
+			{
+				Model:            "mock-fast:3b",
+				Match:            "JSON Schema",
+				Output:           "```json\n{\"tags\": [\"Cache\"], \"component\": \"session cache\", \"impact\": \"severe\", \"type\": \"bugfix\"}\n```",
+				PromptTokens:     160,
+				LoadMS:           800,
+				TimeToFirstToken: 100,
+				TokensPerSecond:  80,
+			},
+			{
+				Model:            "mock-fast:3b",
+				Match:            "is not valid",
+				Output:           `{"tags": ["cache", "sessions"], "component": "cache", "impact": "high", "type": "bugfix"}`,
+				PromptTokens:     160,
+				LoadMS:           800,
+				TimeToFirstToken: 100,
+				TokensPerSecond:  80,
+			},
+			// Tool calling: the quality model calls the right tools, the
+			// fast one invents a tool and gets arguments wrong
+			{
+				Model:            "mock-quality:7b",
+				Match:            "internal/auth",
+				Output:           "This week in internal/auth: refresh tokens now rotate on use, the login endpoint is rate limited and a session expiry bug was fixed.",
+				ToolCalls:        []ToolCall{{ID: "call_0", Name: "git_log", Arguments: map[string]interface{}{"path": "internal/auth", "since": "7 days ago"}}},
+				PromptTokens:     200,
+				LoadMS:           2500,
+				TimeToFirstToken: 400,
+				TokensPerSecond:  40,
+			},
+			{
+				Model:            "mock-fast:3b",
+				Match:            "internal/auth",
+				Output:           "Auth changes were made this week.",
+				ToolCalls:        []ToolCall{{ID: "call_0", Name: "search_commits", Arguments: map[string]interface{}{"query": "auth"}}},
+				PromptTokens:     200,
+				LoadMS:           800,
+				TimeToFirstToken: 100,
+				TokensPerSecond:  80,
+			},
+			{
+				Model:            "mock-quality:7b",
+				Match:            "config/retry.yaml",
+				Output:           "Retries use exponential backoff: up to 5 attempts, starting at 200ms and capped at 10s.",
+				ToolCalls:        []ToolCall{{ID: "call_0", Name: "read_file", Arguments: map[string]interface{}{"path": "config/retry.yaml"}}},
+				PromptTokens:     200,
+				LoadMS:           2500,
+				TimeToFirstToken: 400,
+				TokensPerSecond:  40,
+			},
+			{
+				Model:            "mock-fast:3b",
+				Match:            "config/retry.yaml",
+				Output:           "Retry settings were configured.",
+				ToolCalls:        []ToolCall{{ID: "call_0", Name: "read_file", Arguments: map[string]interface{}{"path": "retry.yaml"}}},
+				PromptTokens:     200,
+				LoadMS:           800,
+				TimeToFirstToken: 100,
+				TokensPerSecond:  80,
+			},
+			{
+				Model:            "mock-quality:7b",
+				Output:           "## What Was Done\n\nImplemented the change described in the input and verified the API and database behaviour.\n\n## Outcomes & Benefits\n\n- Faster service responses\n- Simpler system maintenance",
+				PromptTokens:     60,
+				LoadMS:           2500,
+				TimeToFirstToken: 400,
+				TokensPerSecond:  40,
+			},
+			{
+				Model:            "mock-embed:small",
+				PromptTokens:     40,
+				LoadMS:           300,
+				TimeToFirstToken: 20,
+			},
+		},
+		Default: MockResponse{
+			Output:           "Fixed the reported issue in the service, improving API performance and system reliability.",
+			PromptTokens:     40,
+			LoadMS:           800,
+			TimeToFirstToken: 100,
+			TokensPerSecond:  80,
+		},
+		Slots: 2,
+	}
+}
+
+// loadMockScript reads a script file; an empty path gives the built-in script
+func loadMockScript(path string) (MockScript, error) {
+	if path == "" {
+		return defaultMockScript(), nil
+	}
+
+	var script MockScript
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return script, err
+	}
+	return script, json.Unmarshal(data, &script)
+}
+
+func (b *MockBackend) Name() string {
+	return "mock"
+}
+
+func (b *MockBackend) Generate(ctx context.Context, req GenerateRequest) (GenerateResponse, error) {
+	return b.respond(ctx, req.Model, req.Prompt, req.Prompt, req.Options)
+}
+
+func (b *MockBackend) Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error) {
+	// Responses match on the latest user message; the whole history is prefilled
+	prompt := ""
+	var transcript []string
+	for _, message := range req.Messages {
+		transcript = append(transcript, message.Content)
+		if message.Role == "user" {
+			prompt = message.Content
+		}
+	}
+	resp, err := b.respond(ctx, req.Model, prompt, strings.Join(transcript, "\n"), req.Options)
+	if err != nil || len(req.Tools) == 0 || req.Messages[len(req.Messages)-1].Role == "tool" {
+		return resp, err
+	}
+	if scripted := b.match(req.Model, prompt); len(scripted.ToolCalls) > 0 {
+		resp.Output = ""
+		resp.ToolCalls = scripted.ToolCalls
+	}
+	return resp, nil
+}
+
+// Unload makes the next request for model pay its load time again
+func (b *MockBackend) Unload(ctx context.Context, model string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.loaded, model)
+	return nil
+}
+
+// ListModels reports the scripted models
+func (b *MockBackend) ListModels(ctx context.Context) ([]string, error) {
+	return b.Script.Models, nil
+}
+
+// Inventory reports the scripted model metadata
+func (b *MockBackend) Inventory(ctx context.Context) (*ModelInventory, error) {
+	return newModelInventory(b.Script.Inventory), nil
+}
+
+// mockEmbeddingDimensions is the size of the mock's hashed bag-of-words vectors
+const mockEmbeddingDimensions = 64
+
+// Embed returns bag-of-words vectors, so texts sharing words come out
+// similar. Latency follows the scripted load and prefill time.
+func (b *MockBackend) Embed(ctx context.Context, req EmbedRequest) (EmbedResponse, error) {
+	scripted := b.match(req.Model, strings.Join(req.Input, " "))
+	if scripted.Error != "" {
+		return EmbedResponse{Backend: b.Name()}, fmt.Errorf("mock: %s", scripted.Error)
+	}
+
+	load := b.load(req.Model, scripted)
+	promptTokens := 0
+	for _, input := range req.Input {
+		promptTokens += len(strings.Fields(input))
+	}
+	prefill := time.Duration(scripted.TimeToFirstToken) * time.Millisecond
+	if scripted.PromptTokens > 0 {
+		prefill = prefill * time.Duration(promptTokens) / time.Duration(scripted.PromptTokens)
+	}
+
+	select {
+	case <-time.After(load + prefill):
+	case <-ctx.Done():
+		return EmbedResponse{Backend: b.Name()}, ctx.Err()
+	}
+
+	resp := EmbedResponse{
+		Backend:       b.Name(),
+		PromptTokens:  promptTokens,
+		LoadDuration:  load,
+		TotalDuration: load + prefill,
+	}
+	for _, input := range req.Input {
+		resp.Embeddings = append(resp.Embeddings, mockEmbedding(input))
+	}
+	return resp, nil
+}
+
+func mockEmbedding(text string) []float64 {
+	vector := make([]float64, mockEmbeddingDimensions)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.Trim(word, ".,:;!?()\"'")
+		if word == "" {
+			continue
+		}
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%mockEmbeddingDimensions]++
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+	return vector
+}
+
+// load returns the load time model pays now: its scripted cost on the first
+// request after start or unload, zero otherwise
+func (b *MockBackend) load(model string, scripted MockResponse) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.loaded[model] {
+		return 0
+	}
+	if b.loaded == nil {
+		b.loaded = make(map[string]bool)
+	}
+	b.loaded[model] = true
+	return time.Duration(scripted.LoadMS) * time.Millisecond
+}
+
+func (b *MockBackend) respond(ctx context.Context, model, prompt, transcript string, opts GenerationOptions) (GenerateResponse, error) {
+	scripted := b.match(model, prompt)
+	if scripted.Error != "" {
+		return GenerateResponse{Backend: b.Name()}, fmt.Errorf("mock: %s", scripted.Error)
+	}
+
+	output := scripted.Output
+	outputTokens := scripted.OutputTokens
+	if outputTokens == 0 {
+		outputTokens = len(strings.Fields(output))
+	}
+	if opts.MaxTokens > 0 && outputTokens > opts.MaxTokens {
+		// One word per token keeps the truncation deterministic
+		words := strings.Fields(output)
+		if len(words) > opts.MaxTokens {
+			output = strings.Join(words[:opts.MaxTokens], " ")
+		}
+		outputTokens = opts.MaxTokens
+	}
+	tokensPerSecond := scripted.TokensPerSecond
+	if tokensPerSecond <= 0 {
+		tokensPerSecond = 50
+	}
+
+	load := b.load(model, scripted)
+
+	// Requests beyond the available slots share the decode rate, like a
+	// runtime splitting one device between parallel requests
+	b.mu.Lock()
+	b.inFlight++
+	if b.Script.Slots > 0 && b.inFlight > b.Script.Slots {
+		tokensPerSecond = tokensPerSecond * float64(b.Script.Slots) / float64(b.inFlight)
+	}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.inFlight--
+		b.mu.Unlock()
+	}()
+
+	// Prompts longer than the scripted one take proportionally longer to
+	// prefill, so context sweeps see realistic growth
+	promptTokens := scripted.PromptTokens
+	prefill := time.Duration(scripted.TimeToFirstToken) * time.Millisecond
+	if words := len(strings.Fields(transcript)); promptTokens > 0 && words > promptTokens {
+		prefill = prefill * time.Duration(words) / time.Duration(promptTokens)
+		promptTokens = words
+	}
+	ttft := load + prefill
+	tokenGap := time.Duration(float64(time.Second) / tokensPerSecond)
+	decode := tokenGap * time.Duration(outputTokens)
+
+	// Simulate the wait so wall-clock measurements in the tools line up
+	select {
+	case <-time.After(ttft + decode):
+	case <-ctx.Done():
+		return GenerateResponse{Backend: b.Name()}, ctx.Err()
+	}
+
+	// Synthetic token arrivals give deterministic stream timings
+	recorder := &streamRecorder{start: time.Unix(0, 0)}
+	for i := 0; i < outputTokens; i++ {
+		recorder.arrivals = append(recorder.arrivals, recorder.start.Add(ttft+time.Duration(i)*tokenGap))
+	}
+
+	return GenerateResponse{
+		Output:  output,
+		Backend: b.Name(),
+		Metrics: GenerationMetrics{
+			PromptEvalCount:    promptTokens,
+			EvalCount:          outputTokens,
+			LoadDuration:       load,
+			PromptEvalDuration: prefill,
+			EvalDuration:       decode,
+			TotalDuration:      ttft + decode,
+		},
+		Timing: recorder.timing(),
+	}, nil
+}
+
+func (b *MockBackend) match(model, prompt string) MockResponse {
+	for _, response := range b.Script.Responses {
+		if response.Model != "" && response.Model != model {
+			continue
+		}
+		if response.Match != "" && !strings.Contains(prompt, response.Match) {
+			continue
+		}
+		return response
+	}
+	return b.Script.Default
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// latencyScript has one fast scripted reply with a load cost and a failing one
+var latencyScript = MockScript{
+	Models: []string{"tiny"},
+	Responses: []MockResponse{
+		{Match: "broken", Error: "model crashed"},
+		{
+			Model:            "tiny",
+			Match:            "weather",
+			Output:           "sunny and mild today",
+			PromptTokens:     4,
+			LoadMS:           40,
+			TimeToFirstToken: 10,
+			TokensPerSecond:  200,
+		},
+	},
+	Default: MockResponse{Output: "default reply", OutputTokens: 3, TokensPerSecond: 1000},
+}
+
+func TestMockBackendScriptedResponse(t *testing.T) {
+	backend := &MockBackend{Script: latencyScript}
+	ctx := context.Background()
+
+	start := time.Now()
+	cold, err := backend.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "the weather now"})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cold.Output != "sunny and mild today" || cold.Backend != "mock" {
+		t.Errorf("response = %+v", cold)
+	}
+
+	// Four words at 200 tokens/s after a 40ms load and 10ms prefill
+	want := GenerationMetrics{
+		PromptEvalCount:    4,
+		EvalCount:          4,
+		LoadDuration:       40 * time.Millisecond,
+		PromptEvalDuration: 10 * time.Millisecond,
+		EvalDuration:       20 * time.Millisecond,
+		TotalDuration:      70 * time.Millisecond,
+	}
+	if cold.Metrics != want {
+		t.Errorf("metrics = %+v, want %+v", cold.Metrics, want)
+	}
+	if elapsed < want.TotalDuration {
+		t.Errorf("returned after %v, before the simulated %v", elapsed, want.TotalDuration)
+	}
+	if cold.Timing.TimeToFirstToken != 50*time.Millisecond || cold.Timing.InterTokenLatency.P50 != 5*time.Millisecond {
+		t.Errorf("timing = %+v", cold.Timing)
+	}
+
+	// Loaded now, until it's unloaded again
+	warm, _ := backend.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather"})
+	if warm.Metrics.LoadDuration != 0 || warm.Metrics.TotalDuration != 30*time.Millisecond {
+		t.Errorf("warm metrics = %+v", warm.Metrics)
+	}
+	backend.Unload(ctx, "tiny")
+	if reloaded, _ := backend.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather"}); reloaded.Metrics.LoadDuration != 40*time.Millisecond {
+		t.Errorf("reloaded metrics = %+v", reloaded.Metrics)
+	}
+
+	// Other models and prompts fall through to the default
+	if other, _ := backend.Generate(ctx, GenerateRequest{Model: "big", Prompt: "weather"}); other.Output != "default reply" || other.Metrics.EvalCount != 3 {
+		t.Errorf("default = %+v", other)
+	}
+	if _, err := backend.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "broken prompt"}); err == nil || !strings.Contains(err.Error(), "model crashed") {
+		t.Errorf("scripted error = %v", err)
+	}
+}
+
+func TestMockBackendLatencyScales(t *testing.T) {
+	backend := &MockBackend{Script: latencyScript}
+	ctx := context.Background()
+	backend.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather"})
+
+	// Twice the scripted prompt prefills for twice as long
+	long, _ := backend.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather one two three four five six seven"})
+	if long.Metrics.PromptEvalCount != 8 || long.Metrics.PromptEvalDuration != 20*time.Millisecond {
+		t.Errorf("long prompt metrics = %+v", long.Metrics)
+	}
+
+	// MaxTokens cuts the output a word per token
+	short, _ := backend.Generate(ctx, GenerateRequest{Model: "tiny", Prompt: "weather", Options: GenerationOptions{MaxTokens: 2}})
+	if short.Output != "sunny and" || short.Metrics.EvalCount != 2 || short.Metrics.EvalDuration != 10*time.Millisecond {
+		t.Errorf("truncated = %+v", short)
+	}
+
+	// A cancelled request stops waiting
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := backend.Generate(cancelled, GenerateRequest{Model: "tiny", Prompt: "weather"}); err != context.Canceled {
+		t.Errorf("cancelled error = %v", err)
+	}
+}
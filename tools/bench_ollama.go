@@ -0,0 +1,512 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultOllamaURL = "http://localhost:11434"
+
+// defaultOllamaRegistry serves manifests for models without a registry host
+const defaultOllamaRegistry = "https://registry.ollama.ai"
+
+// OllamaBackend talks to an Ollama server over its REST API
+type OllamaBackend struct {
+	BaseURL     string
+	RegistryURL string // consulted for expected download sizes before pulling
+	Client      *http.Client
+}
+
+// ollamaMetrics mirrors the accounting fields Ollama returns (durations in ns)
+type ollamaMetrics struct {
+	TotalDuration      int64 `json:"total_duration"`
+	LoadDuration       int64 `json:"load_duration"`
+	PromptEvalCount    int   `json:"prompt_eval_count"`
+	PromptEvalDuration int64 `json:"prompt_eval_duration"`
+	EvalCount          int   `json:"eval_count"`
+	EvalDuration       int64 `json:"eval_duration"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+	ollamaMetrics
+}
+
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error,omitempty"`
+	ollamaMetrics
+}
+
+// ollamaMessage is a chat message in Ollama's wire format
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	} `json:"function"`
+}
+
+// NewOllamaBackend creates a client for the given base URL.
+// Accepts the same forms as OLLAMA_HOST ("host:port" or a full URL).
+func NewOllamaBackend(baseURL string) *OllamaBackend {
+	return &OllamaBackend{
+		BaseURL:     normalizeOllamaURL(baseURL),
+		RegistryURL: defaultOllamaRegistry,
+		Client:      &http.Client{},
+	}
+}
+
+// isLocalURL reports whether rawURL points at this machine
+func isLocalURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch parsed.Hostname() {
+	case "localhost", "127.0.0.1", "::1", "0.0.0.0":
+		return true
+	}
+	return false
+}
+
+func normalizeOllamaURL(baseURL string) string {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		return defaultOllamaURL
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+func (b *OllamaBackend) Name() string {
+	return "ollama"
+}
+
+func (b *OllamaBackend) Generate(ctx context.Context, req GenerateRequest) (GenerateResponse, error) {
+	body := map[string]interface{}{
+		"model":   req.Model,
+		"prompt":  req.Prompt,
+		"stream":  true,
+		"options": ollamaOptions(req.Options),
+	}
+	if req.System != "" {
+		body["system"] = req.System
+	}
+
+	recorder := newStreamRecorder()
+	stream, err := b.openStream(ctx, "/api/generate", body)
+	if err != nil {
+		return GenerateResponse{}, err
+	}
+	defer stream.Close()
+
+	var output strings.Builder
+	dec := json.NewDecoder(stream)
+	for {
+		var chunk ollamaGenerateResponse
+		if err := dec.Decode(&chunk); err != nil {
+			return GenerateResponse{}, fmt.Errorf("ollama stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return GenerateResponse{}, fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		recorder.chunk(chunk.Response)
+		output.WriteString(chunk.Response)
+		if chunk.Done {
+			return GenerateResponse{
+				Output:  strings.TrimSpace(output.String()),
+				Backend: b.Name(),
+				Metrics: chunk.ollamaMetrics.toMetrics(),
+				Timing:  recorder.timing(),
+			}, nil
+		}
+	}
+}
+
+func (b *OllamaBackend) Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error) {
+	body := map[string]interface{}{
+		"model":    req.Model,
+		"messages": ollamaMessages(req.Messages),
+		"stream":   true,
+		"options":  ollamaOptions(req.Options),
+	}
+	if len(req.Tools) > 0 {
+		body["tools"] = functionTools(req.Tools)
+	}
+
+	recorder := newStreamRecorder()
+	stream, err := b.openStream(ctx, "/api/chat", body)
+	if err != nil {
+		return GenerateResponse{}, err
+	}
+	defer stream.Close()
+
+	var output strings.Builder
+	var toolCalls []ToolCall
+	dec := json.NewDecoder(stream)
+	for {
+		var chunk ollamaChatResponse
+		if err := dec.Decode(&chunk); err != nil {
+			return GenerateResponse{}, fmt.Errorf("ollama stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return GenerateResponse{}, fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		recorder.chunk(chunk.Message.Content)
+		output.WriteString(chunk.Message.Content)
+		for _, call := range chunk.Message.ToolCalls {
+			toolCalls = append(toolCalls, ToolCall{Name: call.Function.Name, Arguments: call.Function.Arguments})
+		}
+		if chunk.Done {
+			return GenerateResponse{
+				Output:    strings.TrimSpace(output.String()),
+				ToolCalls: toolCalls,
+				Backend:   b.Name(),
+				Metrics:   chunk.ollamaMetrics.toMetrics(),
+				Timing:    recorder.timing(),
+			}, nil
+		}
+	}
+}
+
+// ollamaMessages converts chat messages to Ollama's wire format
+func ollamaMessages(messages []ChatMessage) []ollamaMessage {
+	var converted []ollamaMessage
+	for _, message := range messages {
+		wire := ollamaMessage{Role: message.Role, Content: message.Content, ToolName: message.ToolName}
+		for _, call := range message.ToolCalls {
+			var toolCall ollamaToolCall
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = call.Arguments
+			wire.ToolCalls = append(wire.ToolCalls, toolCall)
+		}
+		converted = append(converted, wire)
+	}
+	return converted
+}
+
+// ollamaOptions maps generation options onto Ollama's option names
+func ollamaOptions(opts GenerationOptions) map[string]interface{} {
+	options := make(map[string]interface{})
+	if opts.MaxTokens > 0 {
+		options["num_predict"] = opts.MaxTokens
+	}
+	if opts.Temperature != nil {
+		options["temperature"] = *opts.Temperature
+	}
+	if opts.TopP != nil {
+		options["top_p"] = *opts.TopP
+	}
+	if opts.Seed != nil {
+		options["seed"] = *opts.Seed
+	}
+	if opts.NumCtx > 0 {
+		options["num_ctx"] = opts.NumCtx
+	}
+	return options
+}
+
+// openStream sends a JSON body and returns the newline-delimited JSON reply
+func (b *OllamaBackend) openStream(ctx context.Context, path string, body interface{}) (io.ReadCloser, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := b.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1024))
+		return nil, fmt.Errorf("ollama %s: %s: %s", path, httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return httpResp.Body, nil
+}
+
+type ollamaModelDetails struct {
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
+}
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name    string             `json:"name"`
+		Size    int64              `json:"size"`
+		Digest  string             `json:"digest"`
+		Details ollamaModelDetails `json:"details"`
+	} `json:"models"`
+}
+
+type ollamaShowResponse struct {
+	Details      ollamaModelDetails     `json:"details"`
+	ModelInfo    map[string]interface{} `json:"model_info"`
+	Capabilities []string               `json:"capabilities"` // e.g. "completion", "embedding"; newer servers only
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings      [][]float64 `json:"embeddings"`
+	TotalDuration   int64       `json:"total_duration"`
+	LoadDuration    int64       `json:"load_duration"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// Embed embeds all inputs in one /api/embed call
+func (b *OllamaBackend) Embed(ctx context.Context, req EmbedRequest) (EmbedResponse, error) {
+	var resp ollamaEmbedResponse
+	if err := b.post(ctx, "/api/embed", req, &resp); err != nil {
+		return EmbedResponse{Backend: b.Name()}, err
+	}
+	if resp.Error != "" {
+		return EmbedResponse{Backend: b.Name()}, fmt.Errorf("ollama: %s", resp.Error)
+	}
+
+	return EmbedResponse{
+		Embeddings:    resp.Embeddings,
+		Backend:       b.Name(),
+		PromptTokens:  resp.PromptEvalCount,
+		LoadDuration:  time.Duration(resp.LoadDuration),
+		TotalDuration: time.Duration(resp.TotalDuration),
+	}, nil
+}
+
+// Unload evicts the model from memory (keep_alive=0)
+func (b *OllamaBackend) Unload(ctx context.Context, model string) error {
+	var resp ollamaGenerateResponse
+	body := map[string]interface{}{"model": model, "keep_alive": 0}
+	if err := b.post(ctx, "/api/generate", body, &resp); err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("ollama: %s", resp.Error)
+	}
+	return nil
+}
+
+// ListModels returns the exact names of installed models from /api/tags
+func (b *OllamaBackend) ListModels(ctx context.Context) ([]string, error) {
+	var tags ollamaTagsResponse
+	if err := b.get(ctx, "/api/tags", &tags); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, model := range tags.Models {
+		names = append(names, model.Name)
+	}
+	return names, nil
+}
+
+// HasModel reports whether model is installed, treating a missing tag as ":latest"
+func (b *OllamaBackend) HasModel(ctx context.Context, model string) (bool, error) {
+	names, err := b.ListModels(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range names {
+		if canonicalModelName(name) == canonicalModelName(model) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Inventory combines /api/tags with /api/show for every installed model
+func (b *OllamaBackend) Inventory(ctx context.Context) (*ModelInventory, error) {
+	var tags ollamaTagsResponse
+	if err := b.get(ctx, "/api/tags", &tags); err != nil {
+		return nil, err
+	}
+
+	var models []ModelInfo
+	for _, tag := range tags.Models {
+		info := ModelInfo{
+			Name:              tag.Name,
+			Digest:            tag.Digest,
+			SizeBytes:         tag.Size,
+			Format:            tag.Details.Format,
+			Family:            tag.Details.Family,
+			Families:          tag.Details.Families,
+			ParameterSize:     tag.Details.ParameterSize,
+			ParameterCount:    parseParameterSize(tag.Details.ParameterSize),
+			QuantizationLevel: tag.Details.QuantizationLevel,
+		}
+
+		// /api/show has the exact parameter count and the context length
+		var show ollamaShowResponse
+		if err := b.post(ctx, "/api/show", map[string]string{"model": tag.Name}, &show); err == nil {
+			architecture, _ := show.ModelInfo["general.architecture"].(string)
+			if count, ok := show.ModelInfo["general.parameter_count"].(float64); ok && count > 0 {
+				info.ParameterCount = int64(count)
+			}
+			if length, ok := show.ModelInfo[architecture+".context_length"].(float64); ok {
+				info.ContextLength = int(length)
+			}
+			info.Capabilities = show.Capabilities
+		}
+
+		models = append(models, info)
+	}
+
+	return newModelInventory(models), nil
+}
+
+// Pull downloads model through /api/pull, streaming status updates
+func (b *OllamaBackend) Pull(ctx context.Context, model string, progress func(PullProgress)) error {
+	stream, err := b.openStream(ctx, "/api/pull", map[string]interface{}{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	decoder := json.NewDecoder(stream)
+	for {
+		var update struct {
+			PullProgress
+			Error string `json:"error,omitempty"`
+		}
+		if err := decoder.Decode(&update); err == io.EOF {
+			return fmt.Errorf("ollama pull: stream ended before success")
+		} else if err != nil {
+			return fmt.Errorf("ollama pull: %w", err)
+		}
+		if update.Error != "" {
+			return fmt.Errorf("ollama: %s", update.Error)
+		}
+		progress(update.PullProgress)
+		if update.Status == "success" {
+			return nil
+		}
+	}
+}
+
+// PullSize sums the layer sizes in the model's registry manifest
+func (b *OllamaBackend) PullSize(ctx context.Context, model string) (int64, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, registryManifestURL(b.RegistryURL, model), nil)
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json")
+
+	httpResp, err := b.Client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("registry manifest for %s: %s", model, httpResp.Status)
+	}
+
+	var manifest struct {
+		Config struct {
+			Size int64 `json:"size"`
+		} `json:"config"`
+		Layers []struct {
+			Size int64 `json:"size"`
+		} `json:"layers"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&manifest); err != nil {
+		return 0, err
+	}
+
+	size := manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	return size, nil
+}
+
+// registryManifestURL maps a model name to its manifest: "mistral" lives at
+// library/mistral:latest on the default registry, "hf.co/org/model:q4" on hf.co
+func registryManifestURL(registry, model string) string {
+	name, tag := canonicalModelName(model), "latest"
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 1:
+		name = "library/" + name
+	case strings.Contains(parts[0], "."):
+		registry = "https://" + parts[0]
+		name = strings.Join(parts[1:], "/")
+	}
+
+	return strings.TrimRight(registry, "/") + "/v2/" + name + "/manifests/" + tag
+}
+
+// get fetches a JSON document
+func (b *OllamaBackend) get(ctx context.Context, path string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, b.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	httpResp, err := b.Client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama %s: %s", path, httpResp.Status)
+	}
+
+	return json.NewDecoder(httpResp.Body).Decode(out)
+}
+
+// post sends a JSON body and decodes a single JSON reply into out
+func (b *OllamaBackend) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	reply, err := b.openStream(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer reply.Close()
+
+	return json.NewDecoder(reply).Decode(out)
+}
+
+func (m ollamaMetrics) toMetrics() GenerationMetrics {
+	return GenerationMetrics{
+		PromptEvalCount:    m.PromptEvalCount,
+		EvalCount:          m.EvalCount,
+		LoadDuration:       time.Duration(m.LoadDuration),
+		PromptEvalDuration: time.Duration(m.PromptEvalDuration),
+		EvalDuration:       time.Duration(m.EvalDuration),
+		TotalDuration:      time.Duration(m.TotalDuration),
+	}
+}
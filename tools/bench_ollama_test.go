@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ndjsonServer stubs one Ollama streaming endpoint, writing each line as
+// NDJSON with a short pause between them so stream timing has something to
+// measure
+func ndjsonServer(t *testing.T, path string, lines []string) (*httptest.Server, *map[string]interface{}) {
+	t.Helper()
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+			w.(http.Flusher).Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &body
+}
+
+func TestOllamaGenerateStream(t *testing.T) {
+	srv, body := ndjsonServer(t, "/api/generate", []string{
+		`{"response":" Fixed","done":false}`,
+		`{"response":" the login","done":false}`,
+		`{"response":" bug. ","done":false}`,
+		`{"response":"","done":true,"total_duration":900000000,"load_duration":300000000,"prompt_eval_count":26,"prompt_eval_duration":100000000,"eval_count":40,"eval_duration":500000000}`,
+	})
+
+	temperature := 0.2
+	backend := NewOllamaBackend(srv.URL)
+	resp, err := backend.Generate(context.Background(), GenerateRequest{
+		Model:   "mistral",
+		Prompt:  "Summarize the commit",
+		System:  "be brief",
+		Options: GenerationOptions{MaxTokens: 64, Temperature: &temperature},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Output != "Fixed the login bug." || resp.Backend != "ollama" {
+		t.Errorf("output = %q from %q", resp.Output, resp.Backend)
+	}
+	// The final chunk's accounting is taken as is
+	want := GenerationMetrics{
+		PromptEvalCount:    26,
+		EvalCount:          40,
+		LoadDuration:       300 * time.Millisecond,
+		PromptEvalDuration: 100 * time.Millisecond,
+		EvalDuration:       500 * time.Millisecond,
+		TotalDuration:      900 * time.Millisecond,
+	}
+	if resp.Metrics != want {
+		t.Errorf("metrics = %+v, want %+v", resp.Metrics, want)
+	}
+	if resp.Metrics.DecodeRate() != 80 || resp.Metrics.PrefillRate() != 260 {
+		t.Errorf("decode %v t/s, prefill %v t/s", resp.Metrics.DecodeRate(), resp.Metrics.PrefillRate())
+	}
+
+	if (*body)["stream"] != true || (*body)["system"] != "be brief" {
+		t.Errorf("body = %v", *body)
+	}
+	if options := (*body)["options"].(map[string]interface{}); options["num_predict"] != 64.0 || options["temperature"] != 0.2 {
+		t.Errorf("options = %v", options)
+	}
+}
+
+func TestOllamaChatStream(t *testing.T) {
+	srv, body := ndjsonServer(t, "/api/chat", []string{
+		`{"message":{"role":"assistant","content":""},"done":false}`,
+		`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Oslo"}}}]},"done":false}`,
+		`{"message":{"role":"assistant","content":"Checking."},"done":false}`,
+		`{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":30,"eval_count":12,"eval_duration":200000000}`,
+	})
+
+	backend := NewOllamaBackend(srv.URL)
+	resp, err := backend.Chat(context.Background(), ChatRequest{
+		Model: "mistral",
+		Messages: []ChatMessage{
+			{Role: "user", Content: "weather in Oslo?"},
+			{Role: "assistant", ToolCalls: []ToolCall{{Name: "get_weather", Arguments: map[string]interface{}{"city": "Bergen"}}}},
+			{Role: "tool", ToolName: "get_weather", Content: `{"forecast": "rain"}`},
+		},
+		Tools: weatherTools,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Output != "Checking." || resp.Metrics.PromptEvalCount != 30 || resp.Metrics.EvalCount != 12 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if want := []ToolCall{{Name: "get_weather", Arguments: map[string]interface{}{"city": "Oslo"}}}; !reflect.DeepEqual(resp.ToolCalls, want) {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+
+	// Earlier tool calls and results go back in Ollama's wire format
+	messages := (*body)["messages"].([]interface{})
+	if len(messages) != 3 {
+		t.Fatalf("messages = %v", messages)
+	}
+	if calls := messages[1].(map[string]interface{})["tool_calls"].([]interface{}); len(calls) != 1 {
+		t.Errorf("assistant message = %v", messages[1])
+	}
+	if messages[2].(map[string]interface{})["tool_name"] != "get_weather" {
+		t.Errorf("tool message = %v", messages[2])
+	}
+	if tools := (*body)["tools"].([]interface{}); len(tools) != 2 {
+		t.Errorf("tools = %v", tools)
+	}
+}
+
+func TestOllamaStreamErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		lines []string
+		err   string
+	}{
+		{"error chunk", "/api/generate", []string{`{"response":"Fi","done":false}`, `{"error":"out of memory"}`}, "ollama: out of memory"},
+		{"cut off before done", "/api/generate", []string{`{"response":"Fi","done":false}`}, "ollama stream: EOF"},
+		{"not json", "/api/generate", []string{`<html>`}, "ollama stream"},
+		{"no such endpoint", "/api/tags", nil, "404 Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := ndjsonServer(t, tt.path, tt.lines)
+			_, err := NewOllamaBackend(srv.URL).Generate(context.Background(), GenerateRequest{Model: "mistral", Prompt: "hi"})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestOllamaStreamTiming(t *testing.T) {
+	// An empty chunk first, as Ollama sends while the prompt is processed
+	lines := []string{`{"response":"","done":false}`}
+	for i := 0; i < 20; i++ {
+		lines = append(lines, fmt.Sprintf(`{"response":"t%d ","done":false}`, i))
+	}
+	lines = append(lines, `{"response":"","done":true,"eval_count":20,"eval_duration":100000000}`)
+	srv, _ := ndjsonServer(t, "/api/generate", lines)
+
+	resp, err := NewOllamaBackend(srv.URL).Generate(context.Background(), GenerateRequest{Model: "mistral", Prompt: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first token arrives after the empty chunk and one pause
+	timing := resp.Timing
+	if timing.TimeToFirstToken < 5*time.Millisecond {
+		t.Errorf("ttft = %v", timing.TimeToFirstToken)
+	}
+	latency := timing.InterTokenLatency
+	if latency.P50 < 2*time.Millisecond || latency.P50 > latency.P90 || latency.P90 > latency.P99 || latency.P99 > latency.Max {
+		t.Errorf("inter-token latency = %+v", latency)
+	}
+	// 20 tokens fill one 16-token window
+	if len(timing.DecodeTimeline) != 1 || timing.DecodeTimeline[0].Tokens != 17 || timing.DecodeTimeline[0].TokensPerSecond <= 0 {
+		t.Errorf("decode timeline = %+v", timing.DecodeTimeline)
+	}
+	if timing.DecodeTimeline[0].Elapsed <= timing.TimeToFirstToken {
+		t.Errorf("window ended at %v, before the first token at %v", timing.DecodeTimeline[0].Elapsed, timing.TimeToFirstToken)
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// OpenAIBackend talks to any server speaking the OpenAI chat completions
+// protocol: llama.cpp's server, LM Studio, vLLM and friends
+type OpenAIBackend struct {
+	BaseURL string // including the /v1 prefix
+	APIKey  string
+	Client  *http.Client
+}
+
+type openAIChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"` // JSON, streamed in fragments
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
+	// llama.cpp's server adds its own timings to the final chunk
+	Timings *struct {
+		PromptN     int     `json:"prompt_n"`
+		PromptMS    float64 `json:"prompt_ms"`
+		PredictedN  int     `json:"predicted_n"`
+		PredictedMS float64 `json:"predicted_ms"`
+	} `json:"timings,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewOpenAIBackend creates a client for baseURL, appending /v1 when missing
+func NewOpenAIBackend(baseURL, apiKey string) *OpenAIBackend {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if !strings.HasSuffix(baseURL, "/v1") {
+		baseURL += "/v1"
+	}
+	return &OpenAIBackend{
+		BaseURL: baseURL,
+		APIKey:  apiKey,
+		Client:  &http.Client{},
+	}
+}
+
+func (b *OpenAIBackend) Name() string {
+	return "openai"
+}
+
+func (b *OpenAIBackend) Generate(ctx context.Context, req GenerateRequest) (GenerateResponse, error) {
+	var messages []ChatMessage
+	if req.System != "" {
+		messages = append(messages, ChatMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, ChatMessage{Role: "user", Content: req.Prompt})
+
+	return b.Chat(ctx, ChatRequest{Model: req.Model, Messages: messages, Options: req.Options})
+}
+
+// Chat streams a chat completion. Servers that don't report timings get
+// prefill time from TTFT and decode time from the first-to-last token span.
+// NumCtx is fixed when these servers start, so it isn't sent.
+func (b *OpenAIBackend) Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error) {
+	body := map[string]interface{}{
+		"model":          req.Model,
+		"messages":       openAIMessages(req.Messages),
+		"stream":         true,
+		"stream_options": map[string]bool{"include_usage": true},
+	}
+	if len(req.Tools) > 0 {
+		body["tools"] = functionTools(req.Tools)
+	}
+	if req.Options.MaxTokens > 0 {
+		body["max_tokens"] = req.Options.MaxTokens
+	}
+	if req.Options.Temperature != nil {
+		body["temperature"] = *req.Options.Temperature
+	}
+	if req.Options.TopP != nil {
+		body["top_p"] = *req.Options.TopP
+	}
+	if req.Options.Seed != nil {
+		body["seed"] = *req.Options.Seed
+	}
+
+	start := time.Now()
+	recorder := newStreamRecorder()
+	stream, err := b.openStream(ctx, "/chat/completions", body)
+	if err != nil {
+		return GenerateResponse{}, err
+	}
+	defer stream.Close()
+
+	var output strings.Builder
+	var metrics GenerationMetrics
+	var serverTimings bool
+	var calls []*openAIToolCall
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if payload == "[DONE]" {
+			break
+		}
+
+		var chunk openAIChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			return GenerateResponse{}, fmt.Errorf("openai stream: %w", err)
+		}
+		if chunk.Error != nil {
+			return GenerateResponse{}, fmt.Errorf("openai: %s", chunk.Error.Message)
+		}
+		for _, choice := range chunk.Choices {
+			recorder.chunk(choice.Delta.Content)
+			output.WriteString(choice.Delta.Content)
+			for _, delta := range choice.Delta.ToolCalls {
+				for len(calls) <= delta.Index {
+					calls = append(calls, &openAIToolCall{})
+				}
+				call := calls[delta.Index]
+				if delta.ID != "" {
+					call.id = delta.ID
+				}
+				call.name += delta.Function.Name
+				call.arguments.WriteString(delta.Function.Arguments)
+			}
+		}
+		if chunk.Usage != nil {
+			metrics.PromptEvalCount = chunk.Usage.PromptTokens
+			metrics.EvalCount = chunk.Usage.CompletionTokens
+		}
+		if chunk.Timings != nil {
+			serverTimings = true
+			metrics.PromptEvalCount = chunk.Timings.PromptN
+			metrics.PromptEvalDuration = time.Duration(chunk.Timings.PromptMS * float64(time.Millisecond))
+			metrics.EvalCount = chunk.Timings.PredictedN
+			metrics.EvalDuration = time.Duration(chunk.Timings.PredictedMS * float64(time.Millisecond))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return GenerateResponse{}, fmt.Errorf("openai stream: %w", err)
+	}
+
+	metrics.TotalDuration = time.Since(start)
+	if !serverTimings && len(recorder.arrivals) > 0 {
+		metrics.PromptEvalDuration = recorder.arrivals[0].Sub(recorder.start)
+		metrics.EvalDuration = recorder.arrivals[len(recorder.arrivals)-1].Sub(recorder.arrivals[0])
+	}
+	// LM Studio and older servers ignore include_usage; servers stream about
+	// one token per chunk, so the chunk count stands in
+	if metrics.EvalCount == 0 && len(recorder.arrivals) > 0 {
+		metrics.EvalCount = len(recorder.arrivals)
+		metrics.EvalCountEstimated = true
+	}
+
+	var toolCalls []ToolCall
+	for i, call := range calls {
+		toolCall := ToolCall{ID: call.id, Name: call.name}
+		if toolCall.ID == "" {
+			toolCall.ID = fmt.Sprintf("call_%d", i)
+		}
+		// Malformed arguments are kept as an error, which scores as invalid arguments
+		if arguments := call.arguments.String(); arguments != "" {
+			if err := json.Unmarshal([]byte(arguments), &toolCall.Arguments); err != nil {
+				toolCall.ArgumentError = fmt.Sprintf("malformed arguments %q: %v", arguments, err)
+			}
+		}
+		toolCalls = append(toolCalls, toolCall)
+	}
+
+	return GenerateResponse{
+		Output:    strings.TrimSpace(output.String()),
+		ToolCalls: toolCalls,
+		Backend:   b.Name(),
+		Metrics:   metrics,
+		Timing:    recorder.timing(),
+	}, nil
+}
+
+// openAIToolCall accumulates a streamed tool call
+type openAIToolCall struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+// openAIMessages converts chat messages to the chat completions format,
+// where tool arguments travel as a JSON string
+func openAIMessages(messages []ChatMessage) []map[string]interface{} {
+	var converted []map[string]interface{}
+	for _, message := range messages {
+		wire := map[string]interface{}{"role": message.Role, "content": message.Content}
+		if message.ToolCallID != "" {
+			wire["tool_call_id"] = message.ToolCallID
+		}
+		var calls []map[string]interface{}
+		for _, call := range message.ToolCalls {
+			arguments, _ := json.Marshal(call.Arguments)
+			calls = append(calls, map[string]interface{}{
+				"id":   call.ID,
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":      call.Name,
+					"arguments": string(arguments),
+				},
+			})
+		}
+		if len(calls) > 0 {
+			wire["tool_calls"] = calls
+		}
+		converted = append(converted, wire)
+	}
+	return converted
+}
+
+// Embed calls /v1/embeddings with all inputs at once
+func (b *OpenAIBackend) Embed(ctx context.Context, req EmbedRequest) (EmbedResponse, error) {
+	start := time.Now()
+	reply, err := b.openStream(ctx, "/embeddings", req)
+	if err != nil {
+		return EmbedResponse{Backend: b.Name()}, err
+	}
+	defer reply.Close()
+
+	var body struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			PromptTokens int `json:"prompt_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.NewDecoder(reply).Decode(&body); err != nil {
+		return EmbedResponse{Backend: b.Name()}, fmt.Errorf("openai embeddings: %w", err)
+	}
+
+	resp := EmbedResponse{
+		Embeddings:    make([][]float64, len(req.Input)),
+		Backend:       b.Name(),
+		PromptTokens:  body.Usage.PromptTokens,
+		TotalDuration: time.Since(start),
+	}
+	for _, item := range body.Data {
+		if item.Index >= 0 && item.Index < len(resp.Embeddings) {
+			resp.Embeddings[item.Index] = item.Embedding
+		}
+	}
+	return resp, nil
+}
+
+// ListModels returns the model ids the server reports under /v1/models
+func (b *OpenAIBackend) ListModels(ctx context.Context) ([]string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, b.BaseURL+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	b.authorize(httpReq)
+
+	httpResp, err := b.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openai /models: %s", httpResp.Status)
+	}
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, model := range list.Data {
+		ids = append(ids, model.ID)
+	}
+	return ids, nil
+}
+
+func (b *OpenAIBackend) openStream(ctx context.Context, path string, body interface{}) (io.ReadCloser, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	b.authorize(httpReq)
+
+	httpResp, err := b.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1024))
+		return nil, fmt.Errorf("openai %s: %s: %s", path, httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return httpResp.Body, nil
+}
+
+func (b *OpenAIBackend) authorize(req *http.Request) {
+	if b.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+b.APIKey)
+	}
+}
+
+// apiKeyFromEnv reads the key named by envVar; an empty name means no key
+func apiKeyFromEnv(envVar string) string {
+	if envVar == "" {
+		return ""
+	}
+	return os.Getenv(envVar)
+}
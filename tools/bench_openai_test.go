@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseServer stubs /v1/chat/completions, streaming each chunk as an SSE event
+// with a short pause between them so stream timing has something to measure
+func sseServer(t *testing.T, chunks []string) (*httptest.Server, *map[string]interface{}) {
+	t.Helper()
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &body
+}
+
+func TestOpenAIContentDeltasWithUsage(t *testing.T) {
+	srv, body := sseServer(t, []string{
+		`{"choices":[{"delta":{"role":"assistant"}}]}`,
+		`{"choices":[{"delta":{"content":"Hel"}}]}`,
+		`{"choices":[{"delta":{"content":"lo, "}}]}`,
+		`{"choices":[{"delta":{"content":"world"}}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":4}}`,
+	})
+
+	backend := NewOpenAIBackend(srv.URL, "")
+	resp, err := backend.Generate(context.Background(), GenerateRequest{Model: "llama", Prompt: "hi", System: "be brief"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Output != "Hello, world" {
+		t.Errorf("output = %q", resp.Output)
+	}
+	if resp.Backend != "openai" {
+		t.Errorf("backend = %q", resp.Backend)
+	}
+	if resp.Metrics.PromptEvalCount != 12 || resp.Metrics.EvalCount != 4 || resp.Metrics.EvalCountEstimated {
+		t.Errorf("counts = %+v", resp.Metrics)
+	}
+	// Without server timings, prefill runs to the first token and decode spans the rest
+	if resp.Metrics.PromptEvalDuration <= 0 || resp.Metrics.EvalDuration <= 0 || resp.Metrics.TotalDuration < resp.Metrics.EvalDuration {
+		t.Errorf("durations = %+v", resp.Metrics)
+	}
+	if resp.Timing.TimeToFirstToken != resp.Metrics.PromptEvalDuration {
+		t.Errorf("ttft = %v, prefill = %v", resp.Timing.TimeToFirstToken, resp.Metrics.PromptEvalDuration)
+	}
+	if resp.Timing.InterTokenLatency.Max <= 0 {
+		t.Errorf("inter-token latency = %+v", resp.Timing.InterTokenLatency)
+	}
+
+	if messages := (*body)["messages"].([]interface{}); len(messages) != 2 {
+		t.Errorf("messages = %v", messages)
+	}
+	if options, _ := (*body)["stream_options"].(map[string]interface{}); options["include_usage"] != true {
+		t.Errorf("stream_options = %v", (*body)["stream_options"])
+	}
+}
+
+func TestOpenAIToolCallFragments(t *testing.T) {
+	srv, body := sseServer(t, []string{
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_a","function":{"name":"get_","arguments":""}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"name":"weather","arguments":"{\"city\":"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":1,"function":{"name":"get_time","arguments":"{}"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":" \"Oslo\"}"}}]}}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":30,"completion_tokens":9}}`,
+	})
+
+	backend := NewOpenAIBackend(srv.URL+"/v1", "")
+	resp, err := backend.Chat(context.Background(), ChatRequest{
+		Model:    "llama",
+		Messages: []ChatMessage{{Role: "user", Content: "weather in Oslo?"}},
+		Tools:    []ToolDefinition{{Name: "get_weather", Description: "current weather"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.ToolCalls) != 2 {
+		t.Fatalf("tool calls = %+v", resp.ToolCalls)
+	}
+	first, second := resp.ToolCalls[0], resp.ToolCalls[1]
+	if first.ID != "call_a" || first.Name != "get_weather" || first.Arguments["city"] != "Oslo" {
+		t.Errorf("first call = %+v", first)
+	}
+	// Servers that omit ids get positional ones
+	if second.ID != "call_1" || second.Name != "get_time" || len(second.Arguments) != 0 {
+		t.Errorf("second call = %+v", second)
+	}
+	if resp.Output != "" || resp.Metrics.EvalCount != 9 {
+		t.Errorf("output = %q, metrics = %+v", resp.Output, resp.Metrics)
+	}
+	if tools, _ := (*body)["tools"].([]interface{}); len(tools) != 1 {
+		t.Errorf("tools = %v", (*body)["tools"])
+	}
+}
+
+func TestOpenAIMalformedToolArguments(t *testing.T) {
+	srv, _ := sseServer(t, []string{
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_a","function":{"name":"get_weather","arguments":"{\"city\": Oslo"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":1,"id":"call_b","function":{"name":"get_time"}}]}}]}`,
+	})
+
+	resp, err := NewOpenAIBackend(srv.URL, "").Chat(context.Background(), ChatRequest{
+		Model:    "llama",
+		Messages: []ChatMessage{{Role: "user", Content: "weather in Oslo?"}},
+		Tools:    []ToolDefinition{{Name: "get_weather"}, {Name: "get_time"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.ToolCalls) != 2 {
+		t.Fatalf("tool calls = %+v", resp.ToolCalls)
+	}
+	if malformed := resp.ToolCalls[0]; !strings.Contains(malformed.ArgumentError, "malformed arguments") || malformed.Arguments != nil {
+		t.Errorf("malformed call = %+v", malformed)
+	}
+	// No arguments at all is a call without parameters, not a parse failure
+	if empty := resp.ToolCalls[1]; empty.ArgumentError != "" {
+		t.Errorf("empty call = %+v", empty)
+	}
+
+	spec := ToolCallSpec{Expected: []ToolCall{{Name: "get_weather", Arguments: map[string]interface{}{"city": "Oslo"}}}}
+	if result := scoreToolCalls(spec, resp.ToolCalls[:1]); result.ArgumentAccuracy != 0 || len(result.InvalidArguments) != 1 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestOpenAILlamaCppTimings(t *testing.T) {
+	srv, _ := sseServer(t, []string{
+		`{"choices":[{"delta":{"content":"one"}}]}`,
+		`{"choices":[{"delta":{"content":" two"}}]}`,
+		`{"choices":[{"delta":{}}],"timings":{"prompt_n":20,"prompt_ms":250,"predicted_n":2,"predicted_ms":100}}`,
+	})
+
+	resp, err := NewOpenAIBackend(srv.URL, "").Generate(context.Background(), GenerateRequest{Model: "llama", Prompt: "count"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := GenerationMetrics{
+		PromptEvalCount:    20,
+		PromptEvalDuration: 250 * time.Millisecond,
+		EvalCount:          2,
+		EvalDuration:       100 * time.Millisecond,
+	}
+	got := resp.Metrics
+	got.TotalDuration = 0
+	if got != want {
+		t.Errorf("metrics = %+v, want %+v", got, want)
+	}
+	if rate := resp.Metrics.DecodeRate(); rate != 20 {
+		t.Errorf("decode rate = %v", rate)
+	}
+	if rate := resp.Metrics.PrefillRate(); rate != 80 {
+		t.Errorf("prefill rate = %v", rate)
+	}
+}
+
+func TestOpenAIEstimatesTokensWithoutUsage(t *testing.T) {
+	srv, _ := sseServer(t, []string{
+		`{"choices":[{"delta":{"content":"a"}}]}`,
+		`{"choices":[{"delta":{"content":"b"}}]}`,
+		`{"choices":[{"delta":{"content":"c"}}]}`,
+	})
+
+	resp, err := NewOpenAIBackend(srv.URL, "").Generate(context.Background(), GenerateRequest{Model: "lmstudio", Prompt: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Metrics.EvalCount != 3 || !resp.Metrics.EvalCountEstimated {
+		t.Errorf("metrics = %+v", resp.Metrics)
+	}
+	if resp.Metrics.DecodeRate() <= 0 {
+		t.Errorf("decode rate = %v", resp.Metrics.DecodeRate())
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sekrit" {
+			http.Error(w, `{"error":{"message":"invalid api key"}}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "data: {\"error\":{\"message\":\"model not loaded\"}}\n\n")
+	}))
+	defer srv.Close()
+
+	_, err := NewOpenAIBackend(srv.URL, "wrong").Generate(context.Background(), GenerateRequest{Model: "llama", Prompt: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "invalid api key") {
+		t.Errorf("non-200 error = %v", err)
+	}
+
+	_, err = NewOpenAIBackend(srv.URL, "sekrit").Generate(context.Background(), GenerateRequest{Model: "llama", Prompt: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "model not loaded") {
+		t.Errorf("in-stream error = %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// ModelPuller is implemented by backends that can download models
+type ModelPuller interface {
+	// PullSize is the expected download size of model, if the registry says
+	PullSize(ctx context.Context, model string) (int64, error)
+	// Pull downloads model, reporting each status update to progress
+	Pull(ctx context.Context, model string, progress func(PullProgress)) error
+}
+
+// PullProgress is one status update of a running pull. Total and Completed
+// refer to the layer named by Digest.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+}
+
+// PullResult records a model pulled by --pull-missing
+type PullResult struct {
+	Model         string        `json:"model"`
+	Success       bool          `json:"success"`
+	Error         string        `json:"error,omitempty"`
+	ExpectedBytes int64         `json:"expected_bytes"` // from the registry manifest, 0 if unknown
+	SizeBytes     int64         `json:"size_bytes"`     // sum of the layer sizes reported while pulling
+	Duration      time.Duration `json:"duration"`
+}
+
+// pullMissingModels downloads each model through the backend that serves it,
+// refusing to start (or stopping) when the model won't fit on disk
+func pullMissingModels(backend Backend, models []string) []PullResult {
+	var results []PullResult
+
+	for _, model := range models {
+		fmt.Printf("  ⬇️  Pulling %s\n", model)
+		result := pullModel(backend, model)
+		results = append(results, result)
+
+		if result.Success {
+			fmt.Printf("  ✅ %s pulled (%dMB in %v)\n", model, result.SizeBytes/1024/1024, result.Duration.Round(time.Second))
+		} else {
+			fmt.Printf("  ❌ %s: %s\n", model, result.Error)
+		}
+	}
+
+	return results
+}
+
+func pullModel(backend Backend, model string) PullResult {
+	result := PullResult{Model: model}
+
+	puller, modelID, store := modelPuller(backend, model)
+	if puller == nil {
+		result.Error = fmt.Sprintf("%s backend can't pull models", backend.Name())
+		return result
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Free space matters only when the models land on this machine
+	free := int64(-1)
+	if store != "" {
+		var err error
+		if free, err = freeDiskBytes(store); err != nil {
+			fmt.Printf("     ⚠️  Could not check free disk space: %v\n", err)
+			free = -1
+		}
+	}
+
+	sizeCtx, sizeCancel := context.WithTimeout(ctx, 15*time.Second)
+	expected, err := puller.PullSize(sizeCtx, modelID)
+	sizeCancel()
+	if err == nil {
+		result.ExpectedBytes = expected
+		fmt.Printf("     Expected size: %dMB", expected/1024/1024)
+		if free >= 0 {
+			fmt.Printf(", %dMB free", free/1024/1024)
+		}
+		fmt.Println()
+		if free >= 0 && expected > free {
+			result.Error = fmt.Sprintf("needs %dMB but only %dMB is free", expected/1024/1024, free/1024/1024)
+			return result
+		}
+	}
+
+	// Layer sizes arrive as the pull goes; stop as soon as they can't fit
+	layers := make(map[string]int64)
+	completed := make(map[string]int64)
+	var diskErr error
+	lastPercent := -1
+
+	start := time.Now()
+	err = puller.Pull(ctx, modelID, func(progress PullProgress) {
+		if progress.Digest == "" || progress.Total == 0 {
+			return
+		}
+		layers[progress.Digest] = progress.Total
+		completed[progress.Digest] = progress.Completed
+
+		total, done := sumBytes(layers), sumBytes(completed)
+		if free >= 0 && total > free && diskErr == nil {
+			diskErr = fmt.Errorf("needs at least %dMB but only %dMB is free", total/1024/1024, free/1024/1024)
+			cancel()
+			return
+		}
+		if percent := int(done * 100 / total); lastPercent < 0 || percent >= lastPercent+5 || (percent == 100 && lastPercent != 100) {
+			lastPercent = percent
+			fmt.Printf("\r     %3d%% (%dMB/%dMB)", percent, done/1024/1024, total/1024/1024)
+		}
+	})
+	if lastPercent >= 0 {
+		fmt.Println()
+	}
+	result.Duration = time.Since(start)
+	result.SizeBytes = sumBytes(layers)
+
+	switch {
+	case diskErr != nil:
+		result.Error = diskErr.Error()
+	case err != nil:
+		result.Error = err.Error()
+	default:
+		result.Success = true
+	}
+	return result
+}
+
+// modelPuller finds the backend that would pull model, the name it knows the
+// model by and, for runtimes on this machine, where it stores models
+func modelPuller(backend Backend, model string) (ModelPuller, string, string) {
+	switch b := backend.(type) {
+	case *RoutedBackend:
+		leaf, modelID := b.Resolve(model)
+		return modelPuller(leaf, modelID)
+	case *RecordingBackend:
+		return modelPuller(b.Inner, model)
+	case *OllamaBackend:
+		return b, model, b.localModelStore()
+	}
+
+	if puller, ok := backend.(ModelPuller); ok {
+		return puller, model, ""
+	}
+	return nil, model, ""
+}
+
+// missingModels lists the wanted models that aren't available
+func missingModels(wanted, available []string) []string {
+	have := make(map[string]bool)
+	for _, model := range available {
+		have[model] = true
+	}
+
+	var missing []string
+	for _, model := range wanted {
+		if !have[model] {
+			missing = append(missing, model)
+		}
+	}
+	return missing
+}
+
+// pulledModels lists the models that were pulled successfully
+func pulledModels(pulls []PullResult) []string {
+	var models []string
+	for _, pull := range pulls {
+		if pull.Success {
+			models = append(models, pull.Model)
+		}
+	}
+	return models
+}
+
+func sumBytes(sizes map[string]int64) int64 {
+	var total int64
+	for _, size := range sizes {
+		total += size
+	}
+	return total
+}
+
+// localModelStore is where a local Ollama keeps its models, or "" when the
+// server runs elsewhere
+func (b *OllamaBackend) localModelStore() string {
+	if !isLocalURL(b.BaseURL) {
+		return ""
+	}
+
+	if dir := os.Getenv("OLLAMA_MODELS"); dir != "" {
+		return dir
+	}
+	// The Linux installer runs the server as the ollama user
+	if _, err := os.Stat("/usr/share/ollama/.ollama/models"); err == nil {
+		return "/usr/share/ollama/.ollama/models"
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".ollama", "models")
+	}
+	return ""
+}
+
+// freeDiskBytes reports the space available on the filesystem holding path,
+// which need not exist yet
+func freeDiskBytes(path string) (int64, error) {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	// Bavail leaves out the blocks reserved for root
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
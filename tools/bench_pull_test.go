@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pullStub stubs Ollama's /api/pull and the registry's manifest endpoint.
+// Lines are streamed as NDJSON with a pause between them; an empty manifest
+// answers 404.
+type pullStub struct {
+	manifest string
+	lines    []string
+	pause    time.Duration
+	pulls    int32
+}
+
+func (s *pullStub) start(t *testing.T) *OllamaBackend {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/pull":
+			atomic.AddInt32(&s.pulls, 1)
+			var body struct {
+				Model  string `json:"model"`
+				Stream bool   `json:"stream"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Model != "mistral" || !body.Stream {
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			for _, line := range s.lines {
+				fmt.Fprintln(w, line)
+				w.(http.Flusher).Flush()
+				time.Sleep(s.pause)
+			}
+		case r.URL.Path == "/v2/library/mistral/manifests/latest" && s.manifest != "":
+			if !strings.Contains(r.Header.Get("Accept"), "manifest.v2+json") {
+				http.Error(w, "unexpected accept header", http.StatusNotAcceptable)
+				return
+			}
+			fmt.Fprint(w, s.manifest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	// Free disk space is checked where a local server keeps its models
+	t.Setenv("OLLAMA_MODELS", t.TempDir())
+	backend := NewOllamaBackend(srv.URL)
+	backend.RegistryURL = srv.URL
+	return backend
+}
+
+const smallManifest = `{"config":{"size":500},"layers":[{"size":3000},{"size":1500}]}`
+
+var successfulPull = []string{
+	`{"status":"pulling manifest"}`,
+	`{"status":"pulling aaa","digest":"sha256:aaa","total":3000,"completed":0}`,
+	`{"status":"pulling aaa","digest":"sha256:aaa","total":3000,"completed":3000}`,
+	`{"status":"pulling bbb","digest":"sha256:bbb","total":1500,"completed":750}`,
+	`{"status":"pulling bbb","digest":"sha256:bbb","total":1500,"completed":1500}`,
+	`{"status":"verifying sha256 digest"}`,
+	`{"status":"success"}`,
+}
+
+func TestOllamaPullReportsProgress(t *testing.T) {
+	stub := &pullStub{manifest: smallManifest, lines: successfulPull}
+	backend := stub.start(t)
+
+	size, err := backend.PullSize(context.Background(), "mistral")
+	if err != nil || size != 5000 {
+		t.Fatalf("PullSize = %d, %v", size, err)
+	}
+
+	var updates []PullProgress
+	err = backend.Pull(context.Background(), "mistral", func(progress PullProgress) {
+		updates = append(updates, progress)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != len(successfulPull) {
+		t.Fatalf("got %d updates, want %d: %+v", len(updates), len(successfulPull), updates)
+	}
+	if got := updates[3]; got.Digest != "sha256:bbb" || got.Total != 1500 || got.Completed != 750 {
+		t.Errorf("update 3 = %+v", got)
+	}
+	if updates[len(updates)-1].Status != "success" {
+		t.Errorf("last update = %+v", updates[len(updates)-1])
+	}
+}
+
+func TestOllamaPullErrorLine(t *testing.T) {
+	stub := &pullStub{manifest: smallManifest, lines: []string{
+		`{"status":"pulling manifest"}`,
+		`{"status":"pulling aaa","digest":"sha256:aaa","total":3000,"completed":1000}`,
+		`{"error":"max retries exceeded: unexpected EOF"}`,
+	}}
+	backend := stub.start(t)
+
+	err := backend.Pull(context.Background(), "mistral", func(PullProgress) {})
+	if err == nil || !strings.Contains(err.Error(), "max retries exceeded") {
+		t.Fatalf("Pull error = %v", err)
+	}
+
+	result := pullModel(backend, "mistral")
+	if result.Success || !strings.Contains(result.Error, "max retries exceeded") {
+		t.Errorf("result = %+v", result)
+	}
+	if result.SizeBytes != 3000 {
+		t.Errorf("size = %d, want the layers seen before the error", result.SizeBytes)
+	}
+}
+
+func TestPullModelRecordsDurationAndSize(t *testing.T) {
+	stub := &pullStub{manifest: smallManifest, lines: successfulPull, pause: 5 * time.Millisecond}
+	backend := stub.start(t)
+
+	result := pullModel(backend, "mistral")
+	if !result.Success || result.Error != "" {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.ExpectedBytes != 5000 {
+		t.Errorf("expected bytes = %d", result.ExpectedBytes)
+	}
+	// The layers reported while pulling, without the manifest's config blob
+	if result.SizeBytes != 4500 {
+		t.Errorf("size = %d", result.SizeBytes)
+	}
+	// The pull ends on the success line, before the stub's last pause
+	if minimum := time.Duration(len(successfulPull)-1) * stub.pause; result.Duration < minimum {
+		t.Errorf("duration = %v, want at least %v", result.Duration, minimum)
+	}
+}
+
+func TestPullModelRefusesWithoutDiskSpace(t *testing.T) {
+	// The manifest alone rules the pull out, so it never starts
+	stub := &pullStub{manifest: `{"config":{"size":1},"layers":[{"size":1152921504606846976}]}`, lines: successfulPull}
+	backend := stub.start(t)
+
+	result := pullModel(backend, "mistral")
+	if result.Success || !strings.Contains(result.Error, "only") || result.ExpectedBytes == 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if pulls := atomic.LoadInt32(&stub.pulls); pulls != 0 {
+		t.Errorf("pull started %d times", pulls)
+	}
+}
+
+func TestPullModelStopsWhenLayersOutgrowDisk(t *testing.T) {
+	// Without a manifest the layer sizes reported mid-pull decide
+	stub := &pullStub{lines: []string{
+		`{"status":"pulling manifest"}`,
+		`{"status":"pulling aaa","digest":"sha256:aaa","total":1152921504606846976,"completed":0}`,
+		`{"status":"pulling aaa","digest":"sha256:aaa","total":1152921504606846976,"completed":4096}`,
+		`{"status":"success"}`,
+	}, pause: 5 * time.Millisecond}
+	backend := stub.start(t)
+
+	result := pullModel(backend, "mistral")
+	if result.Success || !strings.Contains(result.Error, "needs at least") || result.ExpectedBytes != 0 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestFreeDiskBytes(t *testing.T) {
+	dir := t.TempDir()
+	free, err := freeDiskBytes(dir)
+	if err != nil || free <= 0 {
+		t.Fatalf("free = %d, %v", free, err)
+	}
+
+	// A store that doesn't exist yet is measured on its nearest parent
+	missing, err := freeDiskBytes(filepath.Join(dir, "models", "blobs"))
+	if err != nil || missing <= 0 {
+		t.Fatalf("free below a missing directory = %d, %v", missing, err)
+	}
+}
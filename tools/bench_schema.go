@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// validateJSONSchema checks value against the subset of JSON Schema that
+// capture metadata needs: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum and maximum. It returns one message per violation.
+func validateJSONSchema(schema map[string]interface{}, value interface{}) []string {
+	var errs []string
+	validateSchemaAt("$", schema, value, &errs)
+	return errs
+}
+
+// parseJSONSchema decodes a schema declared in a config
+func parseJSONSchema(raw json.RawMessage) (map[string]interface{}, error) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return schema, nil
+}
+
+func validateSchemaAt(path string, schema map[string]interface{}, value interface{}, errs *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if jsonTypeMatches(t, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value))
+			return
+		}
+	}
+
+	if constant, ok := schema["const"]; ok && !jsonEqual(constant, value) {
+		fail("must be %v", constant)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if jsonEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("%v is not one of %v", value, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if key, ok := name.(string); ok {
+					if _, present := v[key]; !present {
+						fail("missing required property %q", key)
+					}
+				}
+			}
+		}
+
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if propertySchema, ok := properties[key].(map[string]interface{}); ok {
+				validateSchemaAt(path+"."+key, propertySchema, v[key], errs)
+			} else if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				fail("unexpected property %q", key)
+			}
+		}
+
+	case []interface{}:
+		if n, ok := schemaNumber(schema, "minItems"); ok && float64(len(v)) < n {
+			fail("needs at least %v items, got %d", n, len(v))
+		}
+		if n, ok := schemaNumber(schema, "maxItems"); ok && float64(len(v)) > n {
+			fail("allows at most %v items, got %d", n, len(v))
+		}
+		if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validateSchemaAt(fmt.Sprintf("%s[%d]", path, i), itemSchema, item, errs)
+			}
+		}
+
+	case string:
+		length := float64(len([]rune(v)))
+		if n, ok := schemaNumber(schema, "minLength"); ok && length < n {
+			fail("needs at least %v characters", n)
+		}
+		if n, ok := schemaNumber(schema, "maxLength"); ok && length > n {
+			fail("allows at most %v characters", n)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("%q does not match %s", v, pattern)
+			}
+		}
+
+	case float64:
+		if n, ok := schemaNumber(schema, "minimum"); ok && v < n {
+			fail("%v is below the minimum %v", v, n)
+		}
+		if n, ok := schemaNumber(schema, "maximum"); ok && v > n {
+			fail("%v is above the maximum %v", v, n)
+		}
+	}
+}
+
+// schemaTypes accepts "type": "string" as well as "type": ["string", "null"]
+func schemaTypes(raw interface{}) []string {
+	switch t := raw.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func schemaNumber(schema map[string]interface{}, keyword string) (float64, bool) {
+	n, ok := schema[keyword].(float64)
+	return n, ok
+}
+
+func jsonTypeMatches(t string, value interface{}) bool {
+	switch t {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return jsonTypeName(value) == t
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonEqual compares decoded JSON values
+func jsonEqual(a, b interface{}) bool {
+	left, errA := json.Marshal(a)
+	right, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(left) == string(right)
+}
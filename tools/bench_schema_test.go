@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		errs   []string
+	}{
+		{"type", `{"type":"string"}`, `"devlog"`, nil},
+		{"wrong type", `{"type":"string"}`, `3`, []string{"$: expected string, got number"}},
+		{"integer", `{"type":"integer"}`, `3`, nil},
+		{"fraction isn't an integer", `{"type":"integer"}`, `3.5`, []string{"$: expected integer, got number"}},
+		{"nullable", `{"type":["string","null"]}`, `null`, nil},
+		{"not nullable", `{"type":["string","number"]}`, `null`, []string{"$: expected string or number, got null"}},
+
+		{"enum", `{"enum":["devlog","blog"]}`, `"blog"`, nil},
+		{"not in enum", `{"enum":["devlog","blog"]}`, `"tweet"`, []string{"$: tweet is not one of [devlog blog]"}},
+		{"const", `{"const":1}`, `2`, []string{"$: must be 1"}},
+
+		{"required", `{"type":"object","required":["title","tags"]}`, `{"title":"x"}`, []string{`$: missing required property "tags"`}},
+		{"nested property", `{"type":"object","properties":{"meta":{"type":"object","properties":{"words":{"type":"integer"}}}}}`,
+			`{"meta":{"words":"many"}}`, []string{"$.meta.words: expected integer, got string"}},
+		{"additional properties allowed", `{"type":"object","properties":{"a":{}}}`, `{"a":1,"b":2}`, nil},
+		{"additional properties refused", `{"type":"object","properties":{"a":{}},"additionalProperties":false}`,
+			`{"a":1,"c":3,"b":2}`, []string{`$: unexpected property "b"`, `$: unexpected property "c"`}},
+
+		{"pattern", `{"type":"string","pattern":"^v\\d+$"}`, `"v12"`, nil},
+		{"pattern mismatch", `{"type":"string","pattern":"^v\\d+$"}`, `"12"`, []string{`$: "12" does not match ^v\d+$`}},
+		// Lengths count characters, not bytes
+		{"length", `{"type":"string","minLength":2,"maxLength":3}`, `"héé"`, nil},
+		{"too short", `{"minLength":2}`, `"a"`, []string{"$: needs at least 2 characters"}},
+		{"too long", `{"maxLength":3}`, `"abcd"`, []string{"$: allows at most 3 characters"}},
+
+		{"in range", `{"type":"number","minimum":0,"maximum":1}`, `1`, nil},
+		{"below minimum", `{"minimum":0}`, `-0.5`, []string{"$: -0.5 is below the minimum 0"}},
+		{"above maximum", `{"maximum":5}`, `6`, []string{"$: 6 is above the maximum 5"}},
+
+		{"items", `{"type":"array","items":{"type":"string"},"minItems":1,"maxItems":2}`, `["a","b"]`, nil},
+		{"bad items", `{"type":"array","items":{"type":"string"}}`, `["a",2]`, []string{"$[1]: expected string, got number"}},
+		{"too few items", `{"minItems":1}`, `[]`, []string{"$: needs at least 1 items, got 0"}},
+		{"too many items", `{"maxItems":1}`, `[1,2]`, []string{"$: allows at most 1 items, got 2"}},
+
+		// A type mismatch stops there rather than piling up follow-on errors
+		{"mismatch stops", `{"type":"object","required":["a"]}`, `[]`, []string{"$: expected object, got array"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parseJSONSchema(json.RawMessage(tt.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			if errs := validateJSONSchema(schema, value); !reflect.DeepEqual(errs, tt.errs) {
+				t.Errorf("errors = %q, want %q", errs, tt.errs)
+			}
+		})
+	}
+
+	if _, err := parseJSONSchema(json.RawMessage(`{"type":`)); err == nil {
+		t.Error("truncated schema parsed")
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// LatencyPercentiles summarizes a latency distribution
+type LatencyPercentiles struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// computeLatencyPercentiles uses nearest-rank percentiles over the samples
+func computeLatencyPercentiles(samples []time.Duration) LatencyPercentiles {
+	if len(samples) == 0 {
+		return LatencyPercentiles{}
+	}
+
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return LatencyPercentiles{
+		P50: percentile(sorted, 0.50),
+		P90: percentile(sorted, 0.90),
+		P99: percentile(sorted, 0.99),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// percentile expects sorted input
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p*float64(len(sorted)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeLatencyPercentiles(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := []struct {
+		name    string
+		samples []time.Duration
+		want    LatencyPercentiles
+	}{
+		{"no samples", nil, LatencyPercentiles{}},
+		{"one sample", []time.Duration{7 * ms}, LatencyPercentiles{P50: 7 * ms, P90: 7 * ms, P99: 7 * ms, Max: 7 * ms}},
+		// Nearest rank over 1..10ms, given out of order
+		{"ten samples", []time.Duration{10 * ms, 3 * ms, 1 * ms, 8 * ms, 5 * ms, 2 * ms, 9 * ms, 4 * ms, 7 * ms, 6 * ms},
+			LatencyPercentiles{P50: 5 * ms, P90: 9 * ms, P99: 10 * ms, Max: 10 * ms}},
+		// One slow token moves the tail but not the median
+		{"outlier", []time.Duration{2 * ms, 2 * ms, 2 * ms, 2 * ms, 300 * ms},
+			LatencyPercentiles{P50: 2 * ms, P90: 300 * ms, P99: 300 * ms, Max: 300 * ms}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeLatencyPercentiles(tt.samples); got != tt.want {
+				t.Errorf("percentiles = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// The caller's samples are left in their order
+	samples := []time.Duration{3 * ms, 1 * ms, 2 * ms}
+	computeLatencyPercentiles(samples)
+	if samples[0] != 3*ms || samples[1] != 1*ms {
+		t.Errorf("samples were sorted in place: %v", samples)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import "time"
+
+// decodeSampleWindow is how many streamed tokens each DecodeSample covers
+const decodeSampleWindow = 16
+
+// StreamTiming is what we can only learn by watching tokens arrive
+type StreamTiming struct {
+	TimeToFirstToken  time.Duration      `json:"time_to_first_token"`
+	InterTokenLatency LatencyPercentiles `json:"inter_token_latency"`
+	DecodeTimeline    []DecodeSample     `json:"decode_timeline,omitempty"`
+}
+
+// DecodeSample is the decode speed over one window of the stream
+type DecodeSample struct {
+	Tokens          int           `json:"tokens"`  // tokens received so far
+	Elapsed         time.Duration `json:"elapsed"` // since the request was sent
+	TokensPerSecond float64       `json:"tokens_per_second"`
+}
+
+// streamRecorder timestamps streamed chunks. Runtimes stream one token per
+// chunk, so chunk arrivals are used as token arrivals.
+type streamRecorder struct {
+	start    time.Time
+	arrivals []time.Time
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{start: time.Now()}
+}
+
+// chunk records the arrival of a piece of streamed output
+func (r *streamRecorder) chunk(text string) {
+	if text == "" {
+		return
+	}
+	r.arrivals = append(r.arrivals, time.Now())
+}
+
+func (r *streamRecorder) timing() StreamTiming {
+	var timing StreamTiming
+	if len(r.arrivals) == 0 {
+		return timing
+	}
+
+	timing.TimeToFirstToken = r.arrivals[0].Sub(r.start)
+
+	gaps := make([]time.Duration, 0, len(r.arrivals)-1)
+	for i := 1; i < len(r.arrivals); i++ {
+		gaps = append(gaps, r.arrivals[i].Sub(r.arrivals[i-1]))
+	}
+	timing.InterTokenLatency = computeLatencyPercentiles(gaps)
+
+	for end := decodeSampleWindow; end < len(r.arrivals); end += decodeSampleWindow {
+		window := r.arrivals[end].Sub(r.arrivals[end-decodeSampleWindow])
+		sample := DecodeSample{
+			Tokens:  end + 1,
+			Elapsed: r.arrivals[end].Sub(r.start),
+		}
+		if window > 0 {
+			sample.TokensPerSecond = float64(decodeSampleWindow) / window.Seconds()
+		}
+		timing.DecodeTimeline = append(timing.DecodeTimeline, sample)
+	}
+
+	return timing
+}
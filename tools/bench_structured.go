@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StructuredOutputSpec turns a test case into a structured-output test: the
+// prompt asks for JSON conforming to Schema, and replies that don't parse or
+// validate are sent back with the errors until MaxRetries runs out
+type StructuredOutputSpec struct {
+	Schema     json.RawMessage        `json:"schema"`
+	Expected   map[string]interface{} `json:"expected,omitempty"`    // field -> value the reply should contain
+	MaxRetries *int                   `json:"max_retries,omitempty"` // default 2; 0 disables retries
+}
+
+// StructuredResult records how reliably a model produced valid JSON
+type StructuredResult struct {
+	Parsed         bool               `json:"parsed"`           // the first reply was JSON
+	Valid          bool               `json:"valid"`            // the first reply matched the schema
+	RetriesToValid int                `json:"retries_to_valid"` // -1 if no reply was valid
+	Attempts       int                `json:"attempts"`
+	Errors         []string           `json:"errors,omitempty"` // problems with the last reply
+	FieldsChecked  int                `json:"fields_checked"`   // expected fields, 0 when the case lists none
+	FieldAccuracy  float64            `json:"field_accuracy"`   // average of FieldScores
+	FieldScores    map[string]float64 `json:"field_scores,omitempty"`
+}
+
+// StructuredRanking ranks a model on structured-output reliability
+type StructuredRanking struct {
+	Model             string  `json:"model"`
+	Tests             int     `json:"tests"`
+	ParseRate         float64 `json:"parse_rate"`          // first replies that were JSON
+	ValidRate         float64 `json:"valid_rate"`          // first replies that matched the schema
+	EventualValidRate float64 `json:"eventual_valid_rate"` // valid within the retries
+	AvgRetries        float64 `json:"avg_retries"`         // retries needed by the eventually valid tests
+	FieldAccuracy     float64 `json:"field_accuracy"`      // over the tests that list expected values
+	Score             float64 `json:"score"`               // 0-5, comparable to the quality rankings
+}
+
+// defaultStructuredRetries is how often an invalid reply is sent back
+const defaultStructuredRetries = 2
+
+// maxReportedSchemaErrors caps the errors quoted back to the model
+const maxReportedSchemaErrors = 5
+
+// runStructuredTest asks for JSON matching spec.Schema and retries with the
+// validation errors. The last response is returned for the aggregate metrics.
+func runStructuredTest(ctx context.Context, backend Backend, model, prompt string, spec StructuredOutputSpec, options GenerationOptions) (StructuredResult, GenerateResponse, error) {
+	result := StructuredResult{RetriesToValid: -1, FieldsChecked: len(spec.Expected)}
+
+	schema, err := parseJSONSchema(spec.Schema)
+	if err != nil {
+		return result, GenerateResponse{}, err
+	}
+	retries := defaultStructuredRetries
+	if spec.MaxRetries != nil {
+		retries = *spec.MaxRetries
+	}
+
+	messages := []ChatMessage{{Role: "user", Content: structuredPrompt(prompt, spec.Schema)}}
+	var resp GenerateResponse
+	var lastValue interface{}
+	parsedAny := false
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		resp, err = backend.Chat(ctx, ChatRequest{Model: model, Messages: messages, Options: options})
+		if err != nil {
+			return result, resp, err
+		}
+		result.Attempts++
+
+		value, parseErr := parseJSONOutput(resp.Output)
+		var problems []string
+		if parseErr != nil {
+			problems = []string{"not valid JSON: " + parseErr.Error()}
+		} else {
+			parsedAny = true
+			lastValue = value
+			problems = validateJSONSchema(schema, value)
+		}
+		result.Errors = problems
+
+		if attempt == 0 {
+			result.Parsed = parseErr == nil
+			result.Valid = len(problems) == 0
+		}
+		if len(problems) == 0 {
+			result.RetriesToValid = attempt
+			break
+		}
+
+		if len(problems) > maxReportedSchemaErrors {
+			problems = problems[:maxReportedSchemaErrors]
+		}
+		messages = append(messages,
+			ChatMessage{Role: "assistant", Content: resp.Output},
+			ChatMessage{Role: "user", Content: "That reply is not valid:\n- " + strings.Join(problems, "\n- ") +
+				"\nReply with only the corrected JSON object."})
+	}
+
+	// Accuracy is judged on the last reply that parsed
+	if parsedAny && len(spec.Expected) > 0 {
+		result.FieldScores = make(map[string]float64)
+		actual, _ := lastValue.(map[string]interface{})
+		var total float64
+		for field, expected := range spec.Expected {
+			score := fieldScore(expected, actual[field])
+			result.FieldScores[field] = score
+			total += score
+		}
+		result.FieldAccuracy = total / float64(len(spec.Expected))
+	}
+
+	return result, resp, nil
+}
+
+// structuredPrompt appends the schema the reply must conform to
+func structuredPrompt(prompt string, schema json.RawMessage) string {
+	return fmt.Sprintf("%s\n\nRespond with only a JSON object that conforms to this JSON Schema:\n%s",
+		prompt, strings.TrimSpace(string(schema)))
+}
+
+// parseJSONOutput decodes a reply, tolerating the markdown code fence most
+// models wrap JSON in
+func parseJSONOutput(output string) (interface{}, error) {
+	text := strings.TrimSpace(output)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+		if newline := strings.Index(text, "\n"); newline >= 0 {
+			text = text[newline+1:] // drop the language tag
+		}
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// fieldScore rates how well actual matches expected, from 0 to 1. Strings
+// match ignoring case, arrays score the share of expected items present
+// and objects average their fields.
+func fieldScore(expected, actual interface{}) float64 {
+	switch want := expected.(type) {
+	case string:
+		if got, ok := actual.(string); ok && strings.EqualFold(strings.TrimSpace(got), strings.TrimSpace(want)) {
+			return 1
+		}
+		return 0
+	case []interface{}:
+		got, ok := actual.([]interface{})
+		if !ok || len(want) == 0 {
+			return 0
+		}
+		found := 0
+		for _, item := range want {
+			for _, candidate := range got {
+				if fieldScore(item, candidate) == 1 {
+					found++
+					break
+				}
+			}
+		}
+		return float64(found) / float64(len(want))
+	case map[string]interface{}:
+		got, ok := actual.(map[string]interface{})
+		if !ok || len(want) == 0 {
+			return 0
+		}
+		var total float64
+		for key, value := range want {
+			total += fieldScore(value, got[key])
+		}
+		return total / float64(len(want))
+	}
+
+	if jsonEqual(expected, actual) {
+		return 1
+	}
+	return 0
+}
+
+// rankStructuredOutput ranks models by first-try validity, validity after
+// retries and field accuracy. Tests that errored count as failures.
+func rankStructuredOutput(results map[string][]StructuredResult) []StructuredRanking {
+	var rankings []StructuredRanking
+
+	for model, modelResults := range results {
+		if len(modelResults) == 0 {
+			continue
+		}
+		ranking := StructuredRanking{Model: model, Tests: len(modelResults)}
+
+		var parsed, valid, eventual, retries, checked int
+		var accuracy float64
+		for _, result := range modelResults {
+			if result.Parsed {
+				parsed++
+			}
+			if result.Valid {
+				valid++
+			}
+			if result.RetriesToValid >= 0 {
+				eventual++
+				retries += result.RetriesToValid
+			}
+			if result.FieldsChecked > 0 {
+				checked++
+				accuracy += result.FieldAccuracy
+			}
+		}
+
+		n := float64(len(modelResults))
+		ranking.ParseRate = float64(parsed) / n
+		ranking.ValidRate = float64(valid) / n
+		ranking.EventualValidRate = float64(eventual) / n
+		if eventual > 0 {
+			ranking.AvgRetries = float64(retries) / float64(eventual)
+		}
+		if checked > 0 {
+			ranking.FieldAccuracy = accuracy / float64(checked)
+			ranking.Score = 5 * (0.4*ranking.ValidRate + 0.2*ranking.EventualValidRate + 0.4*ranking.FieldAccuracy)
+		} else {
+			ranking.Score = 5 * (0.6*ranking.ValidRate + 0.4*ranking.EventualValidRate)
+		}
+
+		rankings = append(rankings, ranking)
+	}
+
+	sort.Slice(rankings, func(i, j int) bool {
+		if rankings[i].Score != rankings[j].Score {
+			return rankings[i].Score > rankings[j].Score
+		}
+		return rankings[i].Model < rankings[j].Model
+	})
+	return rankings
+}
+
+// printStructuredRankings prints the structured-output table
+func printStructuredRankings(rankings []StructuredRanking) {
+	if len(rankings) == 0 {
+		return
+	}
+
+	fmt.Println("\n🧾 Structured Output Reliability:")
+	for i, ranking := range rankings {
+		fmt.Printf("  %d. %s (%.2f) - JSON %.0f%%, valid first try %.0f%%, valid after retries %.0f%% (avg %.1f retries), fields %.0f%%, %d tests\n",
+			i+1, ranking.Model, ranking.Score, ranking.ParseRate*100, ranking.ValidRate*100,
+			ranking.EventualValidRate*100, ranking.AvgRetries, ranking.FieldAccuracy*100, ranking.Tests)
+	}
+}
+
+// formatStructuredResult summarizes one test for progress output
+func formatStructuredResult(result StructuredResult) string {
+	switch {
+	case result.Valid:
+		return fmt.Sprintf("valid JSON, fields %.0f%%", result.FieldAccuracy*100)
+	case result.RetriesToValid > 0:
+		return fmt.Sprintf("valid on retry %d, fields %.0f%%", result.RetriesToValid, result.FieldAccuracy*100)
+	case len(result.Errors) > 0:
+		return "invalid JSON: " + result.Errors[0]
+	}
+	return "invalid JSON"
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   interface{}
+		ok     bool
+	}{
+		{"bare", `{"a": 1}`, map[string]interface{}{"a": 1.0}, true},
+		{"fenced", "```json\n{\"a\": 1}\n```", map[string]interface{}{"a": 1.0}, true},
+		{"fenced without a language", "```\n[1, 2]\n```", []interface{}{1.0, 2.0}, true},
+		{"padded fence", "\n  ```json\n{\"a\": true}\n```  \n", map[string]interface{}{"a": true}, true},
+		{"prose around it", "Here you go: {\"a\": 1}", nil, false},
+		{"truncated", `{"a": `, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseJSONOutput(tt.output)
+			if (err == nil) != tt.ok {
+				t.Fatalf("error = %v", err)
+			}
+			if tt.ok && !reflect.DeepEqual(value, tt.want) {
+				t.Errorf("value = %#v, want %#v", value, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldScore(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual interface{}
+		score            float64
+	}{
+		{"string ignores case and spaces", "Go", " go ", 1},
+		{"different string", "Go", "Rust", 0},
+		{"string vs number", "3", 3.0, 0},
+		{"number", 3.0, 3.0, 1},
+		{"bool", true, false, 0},
+		{"missing", "Go", nil, 0},
+		{"half the items", []interface{}{"go", "sqlite"}, []interface{}{"Go", "docker"}, 0.5},
+		{"items not a list", []interface{}{"go"}, "go", 0},
+		{"object fields", map[string]interface{}{"a": "x", "b": 1.0}, map[string]interface{}{"a": "X", "b": 2.0}, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if score := fieldScore(tt.expected, tt.actual); score != tt.score {
+				t.Errorf("score = %v, want %v", score, tt.score)
+			}
+		})
+	}
+}
+
+// captureSchema asks for a title and a list of tags
+const captureSchema = `{"type":"object","properties":{"title":{"type":"string"},"tags":{"type":"array","items":{"type":"string"}}},"required":["title","tags"],"additionalProperties":false}`
+
+func TestRunStructuredTest(t *testing.T) {
+	script := MockScript{Responses: []MockResponse{
+		// Retries quote the errors back, so they match on "not valid"
+		{Model: "fixer", Match: "not valid", Output: "```json\n{\"title\": \"Fixed login\", \"tags\": [\"auth\"]}\n```"},
+		{Model: "fixer", Output: `{"title": "Fixed login"}`},
+		{Model: "talker", Output: "Sure! The title is Fixed login."},
+		{Model: "fenced", Output: "```json\n{\"title\": \"fixed LOGIN\", \"tags\": [\"auth\", \"bug\"]}\n```"},
+	}}
+	spec := StructuredOutputSpec{
+		Schema:   json.RawMessage(captureSchema),
+		Expected: map[string]interface{}{"title": "Fixed login", "tags": []interface{}{"auth", "bug"}},
+	}
+	noRetries := 0
+
+	tests := []struct {
+		model          string
+		retries        *int
+		parsed, valid  bool
+		retriesToValid int
+		attempts       int
+		fieldAccuracy  float64
+	}{
+		// Valid first time, despite the fence, with both fields right
+		{"fenced", nil, true, true, 0, 1, 1},
+		// Parsed but missing tags, fixed on the first retry with one of two tags
+		{"fixer", nil, true, false, 1, 2, 0.75},
+		{"fixer", &noRetries, true, false, -1, 1, 0.5},
+		// Never JSON, so there's nothing to judge the fields on
+		{"talker", nil, false, false, -1, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			spec := spec
+			spec.MaxRetries = tt.retries
+			result, _, err := runStructuredTest(context.Background(), &MockBackend{Script: script}, tt.model, "Summarize the commit", spec, GenerationOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Parsed != tt.parsed || result.Valid != tt.valid || result.RetriesToValid != tt.retriesToValid || result.Attempts != tt.attempts {
+				t.Errorf("result = %+v", result)
+			}
+			if result.FieldAccuracy != tt.fieldAccuracy {
+				t.Errorf("field accuracy = %v (%v)", result.FieldAccuracy, result.FieldScores)
+			}
+			if (len(result.Errors) == 0) != (tt.retriesToValid >= 0) {
+				t.Errorf("errors = %q", result.Errors)
+			}
+		})
+	}
+
+	if _, _, err := runStructuredTest(context.Background(), &MockBackend{Script: script}, "fenced", "x", StructuredOutputSpec{Schema: json.RawMessage(`{`)}, GenerationOptions{}); err == nil {
+		t.Error("invalid schema accepted")
+	}
+}
+
+func TestRankStructuredOutput(t *testing.T) {
+	first := StructuredResult{Parsed: true, Valid: true, RetriesToValid: 0, FieldsChecked: 2, FieldAccuracy: 1}
+	retried := StructuredResult{Parsed: true, RetriesToValid: 2, FieldsChecked: 2, FieldAccuracy: 0.5}
+	failed := StructuredResult{RetriesToValid: -1, FieldsChecked: 2}
+	unchecked := StructuredResult{Parsed: true, Valid: true, RetriesToValid: 0}
+
+	rankings := rankStructuredOutput(map[string][]StructuredResult{
+		"reliable": {first, first},
+		"retrier":  {first, retried},
+		"broken":   {failed, retried},
+		// Without expected fields validity carries the whole score
+		"schema-only": {unchecked},
+		"none":        {},
+	})
+
+	var order []string
+	for _, ranking := range rankings {
+		order = append(order, ranking.Model)
+	}
+	if !reflect.DeepEqual(order, []string{"reliable", "schema-only", "retrier", "broken"}) {
+		t.Fatalf("order = %v", order)
+	}
+
+	// 5 * (0.4*0.5 + 0.2*1 + 0.4*0.75)
+	retrier := rankings[2]
+	if retrier.ValidRate != 0.5 || retrier.EventualValidRate != 1 || retrier.AvgRetries != 1 || math.Abs(retrier.Score-3.5) > 1e-9 {
+		t.Errorf("retrier = %+v", retrier)
+	}
+	// Only the eventually valid test counts toward the retries
+	broken := rankings[3]
+	if broken.ParseRate != 0.5 || broken.EventualValidRate != 0.5 || broken.AvgRetries != 2 || broken.FieldAccuracy != 0.25 {
+		t.Errorf("broken = %+v", broken)
+	}
+	if rankings[1].Score != 5 {
+		t.Errorf("schema-only = %+v", rankings[1])
+	}
+	if !strings.Contains(formatStructuredResult(retried), "valid on retry 2") {
+		t.Errorf("format = %q", formatStructuredResult(retried))
+	}
+}
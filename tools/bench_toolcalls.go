@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ToolCallSpec turns a test case into a tool-calling test: the model is
+// offered Tools and should make the Expected calls
+type ToolCallSpec struct {
+	Tools    []ToolDefinition `json:"tools"`
+	Expected []ToolCall       `json:"expected"` // in any order; empty means the model should answer without tools
+}
+
+// ToolCallResult scores the calls a model made against the expected ones
+type ToolCallResult struct {
+	Calls             []ToolCall `json:"calls"`
+	ExactMatch        bool       `json:"exact_match"`                  // the expected calls with equal arguments, nothing more
+	NameAccuracy      float64    `json:"name_accuracy"`                // expected calls made to the right tool
+	ArgumentAccuracy  float64    `json:"argument_accuracy"`            // expected arguments matched, over all expected calls
+	InvalidArguments  []string   `json:"invalid_arguments,omitempty"`  // arguments violating the tool's parameter schema
+	HallucinatedTools []string   `json:"hallucinated_tools,omitempty"` // called but never offered
+	UnexpectedCalls   int        `json:"unexpected_calls"`             // calls to offered tools that weren't expected
+}
+
+// ToolRanking ranks a model on tool-calling reliability
+type ToolRanking struct {
+	Model             string  `json:"model"`
+	Tests             int     `json:"tests"`
+	ExactMatchRate    float64 `json:"exact_match_rate"`
+	NameAccuracy      float64 `json:"name_accuracy"`
+	ArgumentAccuracy  float64 `json:"argument_accuracy"`
+	HallucinationRate float64 `json:"hallucination_rate"` // tests with at least one made-up tool
+	Score             float64 `json:"score"`              // 0-5, comparable to the quality rankings
+}
+
+// unknownToolResult is handed back for calls to tools that weren't offered
+const unknownToolResult = `{"error": "no such tool"}`
+
+// defaultStubResult stands in for offered tools without a StubResult
+const defaultStubResult = `{"status": "ok"}`
+
+// runToolCallTest offers the tools, scores the calls the model makes and
+// answers them with the stubbed results so the model can finish its reply.
+// No tool is ever executed. The last response is returned for the aggregate
+// metrics.
+func runToolCallTest(ctx context.Context, backend Backend, model, prompt string, spec ToolCallSpec, options GenerationOptions) (ToolCallResult, GenerateResponse, error) {
+	messages := []ChatMessage{{Role: "user", Content: prompt}}
+	resp, err := backend.Chat(ctx, ChatRequest{Model: model, Messages: messages, Options: options, Tools: spec.Tools})
+	if err != nil {
+		return ToolCallResult{}, resp, err
+	}
+
+	result := scoreToolCalls(spec, resp.ToolCalls)
+	if len(resp.ToolCalls) == 0 {
+		return result, resp, nil
+	}
+
+	stubs := make(map[string]string)
+	for _, tool := range spec.Tools {
+		stubs[tool.Name] = tool.StubResult
+	}
+	messages = append(messages, ChatMessage{Role: "assistant", Content: resp.Output, ToolCalls: resp.ToolCalls})
+	for _, call := range resp.ToolCalls {
+		content, offered := stubs[call.Name]
+		switch {
+		case !offered:
+			content = unknownToolResult
+		case content == "":
+			content = defaultStubResult
+		}
+		messages = append(messages, ChatMessage{Role: "tool", Content: content, ToolName: call.Name, ToolCallID: call.ID})
+	}
+
+	resp, err = backend.Chat(ctx, ChatRequest{Model: model, Messages: messages, Options: options, Tools: spec.Tools})
+	if err != nil {
+		// The run didn't finish, so its calls don't count
+		return ToolCallResult{}, resp, err
+	}
+	return result, resp, nil
+}
+
+// scoreToolCalls matches each expected call to an unused call of the same
+// tool and checks its arguments
+func scoreToolCalls(spec ToolCallSpec, calls []ToolCall) ToolCallResult {
+	result := ToolCallResult{Calls: calls}
+
+	schemas := make(map[string]map[string]interface{})
+	offered := make(map[string]bool)
+	for _, tool := range spec.Tools {
+		offered[tool.Name] = true
+		if len(tool.Parameters) > 0 {
+			if schema, err := parseJSONSchema(tool.Parameters); err == nil {
+				schemas[tool.Name] = schema
+			}
+		}
+	}
+
+	used := make([]bool, len(calls))
+	exact := true
+	var names, arguments float64
+	for _, expected := range spec.Expected {
+		match := -1
+		for i, call := range calls {
+			if !used[i] && call.Name == expected.Name {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			exact = false
+			continue
+		}
+		used[match] = true
+		names++
+
+		score, equal := argumentScore(expected.Arguments, calls[match])
+		arguments += score
+		exact = exact && equal
+	}
+
+	for i, call := range calls {
+		if call.ArgumentError != "" {
+			result.InvalidArguments = append(result.InvalidArguments, call.Name+" "+call.ArgumentError)
+		} else if schema, ok := schemas[call.Name]; ok {
+			for _, problem := range validateJSONSchema(schema, call.Arguments) {
+				result.InvalidArguments = append(result.InvalidArguments, call.Name+" "+problem)
+			}
+		}
+		if used[i] {
+			continue
+		}
+		exact = false
+		if offered[call.Name] {
+			result.UnexpectedCalls++
+		} else {
+			result.HallucinatedTools = append(result.HallucinatedTools, call.Name)
+		}
+	}
+
+	result.ExactMatch = exact
+	if len(spec.Expected) > 0 {
+		result.NameAccuracy = names / float64(len(spec.Expected))
+		result.ArgumentAccuracy = arguments / float64(len(spec.Expected))
+	} else if len(calls) == 0 {
+		// Answering without tools was the right call
+		result.NameAccuracy, result.ArgumentAccuracy = 1, 1
+	}
+	return result
+}
+
+// argumentScore rates the call's arguments against the expected ones from 0
+// to 1 (strings ignoring case) and reports whether all of them are exactly
+// equal. Arguments the case doesn't list are not judged; arguments that
+// didn't parse score 0.
+func argumentScore(expected map[string]interface{}, call ToolCall) (float64, bool) {
+	if call.ArgumentError != "" {
+		return 0, false
+	}
+	if len(expected) == 0 {
+		return 1, true
+	}
+
+	var total float64
+	equal := true
+	for name, value := range expected {
+		total += fieldScore(value, call.Arguments[name])
+		equal = equal && jsonEqual(value, call.Arguments[name])
+	}
+	return total / float64(len(expected)), equal
+}
+
+// rankToolCalling ranks models by exact matches, argument accuracy and how
+// rarely they invent tools. Tests that errored count as failures.
+func rankToolCalling(results map[string][]ToolCallResult) []ToolRanking {
+	var rankings []ToolRanking
+
+	for model, modelResults := range results {
+		if len(modelResults) == 0 {
+			continue
+		}
+		ranking := ToolRanking{Model: model, Tests: len(modelResults)}
+
+		var exact, hallucinated int
+		for _, result := range modelResults {
+			if result.ExactMatch {
+				exact++
+			}
+			if len(result.HallucinatedTools) > 0 {
+				hallucinated++
+			}
+			ranking.NameAccuracy += result.NameAccuracy
+			ranking.ArgumentAccuracy += result.ArgumentAccuracy
+		}
+
+		n := float64(len(modelResults))
+		ranking.ExactMatchRate = float64(exact) / n
+		ranking.NameAccuracy /= n
+		ranking.ArgumentAccuracy /= n
+		ranking.HallucinationRate = float64(hallucinated) / n
+		ranking.Score = 5 * (0.4*ranking.ExactMatchRate + 0.4*ranking.ArgumentAccuracy + 0.2*(1-ranking.HallucinationRate))
+
+		rankings = append(rankings, ranking)
+	}
+
+	sort.Slice(rankings, func(i, j int) bool {
+		if rankings[i].Score != rankings[j].Score {
+			return rankings[i].Score > rankings[j].Score
+		}
+		return rankings[i].Model < rankings[j].Model
+	})
+	return rankings
+}
+
+// formatToolCallResult summarizes one test for progress output
+func formatToolCallResult(result ToolCallResult) string {
+	switch {
+	case result.ExactMatch && len(result.Calls) == 0:
+		return "answered without tools, as expected"
+	case result.ExactMatch:
+		return "exact tool match"
+	}
+
+	summary := fmt.Sprintf("tools %.0f%%, args %.0f%%", result.NameAccuracy*100, result.ArgumentAccuracy*100)
+	if len(result.HallucinatedTools) > 0 {
+		summary += ", made up " + strings.Join(result.HallucinatedTools, ", ")
+	}
+	if result.UnexpectedCalls > 0 {
+		summary += fmt.Sprintf(", %d unexpected calls", result.UnexpectedCalls)
+	}
+	return summary
+}
+
+// printToolRankings prints the tool-calling table
+func printToolRankings(rankings []ToolRanking) {
+	if len(rankings) == 0 {
+		return
+	}
+
+	fmt.Println("\n🛠️  Tool Calling Reliability:")
+	for i, ranking := range rankings {
+		fmt.Printf("  %d. %s (%.2f) - exact %.0f%%, right tool %.0f%%, arguments %.0f%%, hallucinated tools in %.0f%%, %d tests\n",
+			i+1, ranking.Model, ranking.Score, ranking.ExactMatchRate*100, ranking.NameAccuracy*100,
+			ranking.ArgumentAccuracy*100, ranking.HallucinationRate*100, ranking.Tests)
+	}
+}
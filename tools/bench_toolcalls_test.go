@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var weatherTools = []ToolDefinition{
+	{
+		Name:       "get_weather",
+		Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"},"days":{"type":"integer","minimum":1}},"required":["city"]}`),
+		StubResult: `{"forecast": "rain"}`,
+	},
+	{Name: "get_time"},
+}
+
+func weatherCall(arguments map[string]interface{}) ToolCall {
+	return ToolCall{Name: "get_weather", Arguments: arguments}
+}
+
+func TestScoreToolCalls(t *testing.T) {
+	oslo := map[string]interface{}{"city": "Oslo"}
+	expectOslo := ToolCallSpec{Tools: weatherTools, Expected: []ToolCall{weatherCall(oslo)}}
+
+	tests := []struct {
+		name         string
+		spec         ToolCallSpec
+		calls        []ToolCall
+		exact        bool
+		names, args  float64
+		invalid      int
+		hallucinated []string
+		unexpected   int
+	}{
+		{"exact", expectOslo, []ToolCall{weatherCall(oslo)}, true, 1, 1, 0, nil, 0},
+		// Strings match ignoring case, but only exact arguments are an exact match
+		{"case differs", expectOslo, []ToolCall{weatherCall(map[string]interface{}{"city": "oslo"})}, false, 1, 1, 0, nil, 0},
+		{"wrong city", expectOslo, []ToolCall{weatherCall(map[string]interface{}{"city": "Bergen"})}, false, 1, 0, 0, nil, 0},
+		{"no call", expectOslo, nil, false, 0, 0, 0, nil, 0},
+		{"schema violation", expectOslo, []ToolCall{weatherCall(map[string]interface{}{"city": "Oslo", "days": 0})}, true, 1, 1, 1, nil, 0},
+		{"malformed arguments", expectOslo, []ToolCall{{Name: "get_weather", ArgumentError: `malformed arguments "{city"`}}, false, 1, 0, 1, nil, 0},
+		{"made-up tool", expectOslo, []ToolCall{weatherCall(oslo), {Name: "search_web"}}, false, 1, 1, 0, []string{"search_web"}, 0},
+		{"extra call", expectOslo, []ToolCall{weatherCall(oslo), {Name: "get_time"}}, false, 1, 1, 0, nil, 1},
+		{"two expected, one made", ToolCallSpec{Tools: weatherTools, Expected: []ToolCall{weatherCall(oslo), {Name: "get_time"}}},
+			[]ToolCall{{Name: "get_time"}}, false, 0.5, 0.5, 0, nil, 0},
+		// Answering without tools was what the case wanted
+		{"no tools expected", ToolCallSpec{Tools: weatherTools}, nil, true, 1, 1, 0, nil, 0},
+		{"tool not wanted", ToolCallSpec{Tools: weatherTools}, []ToolCall{{Name: "get_time"}}, false, 0, 0, 0, nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scoreToolCalls(tt.spec, tt.calls)
+			if result.ExactMatch != tt.exact || result.NameAccuracy != tt.names || result.ArgumentAccuracy != tt.args {
+				t.Errorf("exact %v, names %v, args %v; want %v, %v, %v",
+					result.ExactMatch, result.NameAccuracy, result.ArgumentAccuracy, tt.exact, tt.names, tt.args)
+			}
+			if len(result.InvalidArguments) != tt.invalid {
+				t.Errorf("invalid arguments = %q", result.InvalidArguments)
+			}
+			if !reflect.DeepEqual(result.HallucinatedTools, tt.hallucinated) || result.UnexpectedCalls != tt.unexpected {
+				t.Errorf("hallucinated %q, unexpected %d", result.HallucinatedTools, result.UnexpectedCalls)
+			}
+		})
+	}
+}
+
+func TestArgumentScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected map[string]interface{}
+		call     ToolCall
+		score    float64
+		equal    bool
+	}{
+		{"nothing expected", nil, ToolCall{Arguments: map[string]interface{}{"x": 1.0}}, 1, true},
+		{"equal", map[string]interface{}{"city": "Oslo", "days": 3.0}, ToolCall{Arguments: map[string]interface{}{"city": "Oslo", "days": 3.0}}, 1, true},
+		{"half right", map[string]interface{}{"city": "Oslo", "days": 3.0}, ToolCall{Arguments: map[string]interface{}{"city": "Oslo", "days": 4.0}}, 0.5, false},
+		{"unjudged extras", map[string]interface{}{"city": "Oslo"}, ToolCall{Arguments: map[string]interface{}{"city": "Oslo", "units": "metric"}}, 1, true},
+		{"list items", map[string]interface{}{"tags": []interface{}{"go", "ai"}}, ToolCall{Arguments: map[string]interface{}{"tags": []interface{}{"Go"}}}, 0.5, false},
+		{"malformed", map[string]interface{}{"city": "Oslo"}, ToolCall{ArgumentError: "malformed arguments"}, 0, false},
+		{"malformed, nothing expected", nil, ToolCall{ArgumentError: "malformed arguments"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if score, equal := argumentScore(tt.expected, tt.call); score != tt.score || equal != tt.equal {
+				t.Errorf("score %v, equal %v; want %v, %v", score, equal, tt.score, tt.equal)
+			}
+		})
+	}
+}
+
+func TestRankToolCalling(t *testing.T) {
+	perfect := ToolCallResult{ExactMatch: true, NameAccuracy: 1, ArgumentAccuracy: 1}
+	rankings := rankToolCalling(map[string][]ToolCallResult{
+		"steady":  {perfect, perfect},
+		"dreamer": {perfect, {NameAccuracy: 1, HallucinatedTools: []string{"search_web"}}},
+		// An errored run is an empty result and counts against the model
+		"flaky": {perfect, {}},
+		"idle":  {},
+	})
+
+	var order []string
+	for _, ranking := range rankings {
+		order = append(order, ranking.Model)
+	}
+	if !reflect.DeepEqual(order, []string{"steady", "flaky", "dreamer"}) {
+		t.Fatalf("order = %v", order)
+	}
+	if rankings[0].Score != 5 || rankings[0].Tests != 2 {
+		t.Errorf("steady = %+v", rankings[0])
+	}
+	// 5 * (0.4*0.5 + 0.4*0.5 + 0.2*1)
+	if flaky := rankings[1]; flaky.ExactMatchRate != 0.5 || flaky.ArgumentAccuracy != 0.5 || math.Abs(flaky.Score-3) > 1e-9 {
+		t.Errorf("flaky = %+v", flaky)
+	}
+	// 5 * (0.4*0.5 + 0.4*0.5 + 0.2*0.5)
+	if dreamer := rankings[2]; dreamer.HallucinationRate != 0.5 || math.Abs(dreamer.Score-2.5) > 1e-9 {
+		t.Errorf("dreamer = %+v", dreamer)
+	}
+}
+
+// failingFollowUp fails chats that carry tool results
+type failingFollowUp struct {
+	*MockBackend
+}
+
+func (b failingFollowUp) Chat(ctx context.Context, req ChatRequest) (GenerateResponse, error) {
+	if req.Messages[len(req.Messages)-1].Role == "tool" {
+		return GenerateResponse{Backend: b.Name()}, errors.New("connection reset")
+	}
+	return b.MockBackend.Chat(ctx, req)
+}
+
+func TestRunToolCallTest(t *testing.T) {
+	script := MockScript{Responses: []MockResponse{
+		{
+			Match:     "Oslo",
+			Output:    "It will rain in Oslo.",
+			ToolCalls: []ToolCall{{ID: "call_0", Name: "get_weather", Arguments: map[string]interface{}{"city": "Oslo"}}},
+		},
+		{Match: "hello", Output: "Hi there."},
+	}}
+	spec := ToolCallSpec{Tools: weatherTools, Expected: []ToolCall{weatherCall(map[string]interface{}{"city": "Oslo"})}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		backend Backend
+		prompt  string
+		exact   bool
+		output  string
+		err     string
+	}{
+		// The tools are answered with their stubs and the model finishes its reply
+		{"called", &MockBackend{Script: script}, "weather in Oslo?", true, "It will rain in Oslo.", ""},
+		{"answered without tools", &MockBackend{Script: script}, "hello", false, "Hi there.", ""},
+		{"follow-up failed", failingFollowUp{&MockBackend{Script: script}}, "weather in Oslo?", false, "", "connection reset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, resp, err := runToolCallTest(ctx, tt.backend, "mock", tt.prompt, spec, GenerationOptions{})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v", err)
+				}
+				// Nothing from the failed run is scored
+				if !reflect.DeepEqual(result, ToolCallResult{}) {
+					t.Errorf("result = %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.ExactMatch != tt.exact || resp.Output != tt.output {
+				t.Errorf("exact %v, output %q", result.ExactMatch, resp.Output)
+			}
+		})
+	}
+}
@@ -16,106 +16,217 @@ import (
 
 // LowSpecBenchmark represents the main benchmarking framework
 type LowSpecBenchmark struct {
-	DeviceProfile HardwareProfile     `json:"device_profile"`
-	TestResults   []BenchmarkResult   `json:"test_results"`
-	Summary       BenchmarkSummary    `json:"summary"`
-	Timestamp     time.Time           `json:"timestamp"`
+	DeviceProfile    HardwareProfile   `json:"device_profile"`
+	TestResults      []BenchmarkResult `json:"test_results"` // warm runs only
+	ColdStarts       []BenchmarkResult `json:"cold_starts"`  // one per model, run right after unloading it
+	EmbeddingResults []EmbeddingResult `json:"embedding_results,omitempty"`
+	Summary          BenchmarkSummary  `json:"summary"`
+	Timestamp        time.Time         `json:"timestamp"`
 }
 
 // HardwareProfile captures device specifications
 type HardwareProfile struct {
-	DeviceName      string  `json:"device_name"`
-	OS              string  `json:"os"`
-	Architecture    string  `json:"architecture"`
-	CPUCores        int     `json:"cpu_cores"`
-	TotalRAM        int64   `json:"total_ram_mb"`
-	AvailableRAM    int64   `json:"available_ram_mb"`
-	StorageType     string  `json:"storage_type"`
-	ThermalProfile  string  `json:"thermal_profile"` // "laptop", "desktop", "mobile", "sbc"
-	PowerProfile    string  `json:"power_profile"`   // "battery", "plugged", "unlimited"
-	Notes           string  `json:"notes"`
+	DeviceName      string           `json:"device_name"`
+	OS              string           `json:"os"`
+	Architecture    string           `json:"architecture"`
+	CPUCores        int              `json:"cpu_cores"`
+	TotalRAM        int64            `json:"total_ram_mb"`
+	AvailableRAM    int64            `json:"available_ram_mb"`
+	StorageType     string           `json:"storage_type"`
+	ThermalProfile  string           `json:"thermal_profile"`            // "laptop", "desktop", "mobile", "sbc"
+	PowerProfile    string           `json:"power_profile"`              // "battery", "plugged", "unlimited"
+	CPUGovernor     *CPUGovernorInfo `json:"cpu_governor,omitempty"`     // cpufreq policy; nil where it can't be read
+	Power           *PowerStatus     `json:"power,omitempty"`            // power supplies at profiling time; nil where none are reported
+	TDPWatts        float64          `json:"tdp_watts,omitempty"`        // --tdp, or guessed from the thermal profile; only used without energy counters
+	Cgroup          *CgroupLimits    `json:"cgroup,omitempty"`           // container limits; nil when not limited. TotalRAM and CPUCores stay the host's
+	CPUCapabilities *CPUCapabilities `json:"cpu_capabilities,omitempty"` // SIMD extensions, caches and memory channels; nil where cpuinfo can't be read
+	Notes           string           `json:"notes"`
 }
 
 // BenchmarkResult represents a single model test result
 type BenchmarkResult struct {
-	Model              string        `json:"model"`
-	ModelSize          string        `json:"model_size"`          // "3b", "7b", "13b"
-	Quantization       string        `json:"quantization"`        // "fp16", "int8", "int4"
-	TestCase           string        `json:"test_case"`
-	Prompt             string        `json:"prompt"`
-	Output             string        `json:"output"`
-	Success            bool          `json:"success"`
-	Error              string        `json:"error,omitempty"`
+	Model        string            `json:"model"`
+	Backend      string            `json:"backend"`
+	Phase        string            `json:"phase"`        // "cold" (model just loaded) or "warm"
+	ModelSize    string            `json:"model_size"`   // "3b", "7b", "13b"
+	Quantization string            `json:"quantization"` // as reported: "Q4_K_M", "Q8_0", "F16"
+	ModelInfo    ModelInfo         `json:"model_info"`   // runtime-reported metadata, empty if unavailable
+	TestCase     string            `json:"test_case"`
+	Prompt       string            `json:"prompt"`
+	Options      GenerationOptions `json:"options"` // effective options sent to the backend
+	Output       string            `json:"output"`
+	Success      bool              `json:"success"`
+	Error        string            `json:"error,omitempty"`
 
 	// Performance Metrics
-	ResponseTime       time.Duration `json:"response_time"`
-	TimeToFirstToken   time.Duration `json:"time_to_first_token"`
-	TokensPerSecond    float64       `json:"tokens_per_second"`
+	ResponseTime      time.Duration      `json:"response_time"`
+	TimeToFirstToken  time.Duration      `json:"time_to_first_token"`
+	TokensPerSecond   float64            `json:"tokens_per_second"`
+	InterTokenLatency LatencyPercentiles `json:"inter_token_latency"`
+	DecodeTimeline    []DecodeSample     `json:"decode_timeline,omitempty"`
 
 	// Resource Usage
-	PeakMemoryMB       int64         `json:"peak_memory_mb"`
-	AvgCPUPercent      float64       `json:"avg_cpu_percent"`
-	MemoryEfficiency   float64       `json:"memory_efficiency"`   // output_quality / memory_used
+	PeakMemoryMB     int64          `json:"peak_memory_mb"`           // runtime's peak footprint, or the drop in available RAM if its processes can't be read
+	ProcessMemory    *ProcessMemory `json:"process_memory,omitempty"` // sampled runtime memory; nil for remote hosts and other backends
+	AvgCPUPercent    float64        `json:"avg_cpu_percent"`          // all cores; see CPU for the breakdown
+	CPU              *CPUUsage      `json:"cpu,omitempty"`            // nil where /proc/stat can't be read
+	Energy           *EnergyUsage   `json:"energy,omitempty"`         // RAPL counters, or a TDP-based estimate
+	MemoryEfficiency float64        `json:"memory_efficiency"`        // output_quality / memory_used
 
 	// Low-Spec Specific Metrics
-	ThermalThrottling  bool          `json:"thermal_throttling"`
-	SwapUsed           bool          `json:"swap_used"`
-	OOMRisk            string        `json:"oom_risk"`            // "low", "medium", "high"
-	BatteryImpact      string        `json:"battery_impact"`      // "minimal", "moderate", "high"
+	ThermalThrottling bool            `json:"thermal_throttling"`
+	Thermal           *ThermalReading `json:"thermal,omitempty"` // sensors and cpufreq; nil where neither can be read
+	SwapUsed          bool            `json:"swap_used"`
+	MemoryPressure    *MemoryPressure `json:"memory_pressure,omitempty"` // kernel counters; nil where /proc/vmstat can't be read
+	OOMRisk           string          `json:"oom_risk"`                  // "low", "medium", "high"
+	BatteryImpact     string          `json:"battery_impact"`            // "minimal", "moderate", "high"
+	Power             *PowerUsage     `json:"power,omitempty"`           // battery before and after; nil where no supply is reported
 
 	// Quality Metrics
-	OutputLength       int           `json:"output_length"`
-	QualityScore       float64       `json:"quality_score"`       // 1-5 automated assessment
-	UsabilityScore     float64       `json:"usability_score"`     // response_time vs quality trade-off
+	OutputLength   int     `json:"output_length"`
+	QualityScore   float64 `json:"quality_score"`   // 1-5 automated assessment
+	UsabilityScore float64 `json:"usability_score"` // response_time vs quality trade-off
 
-	Timestamp          time.Time     `json:"timestamp"`
+	// Conversation Metrics (scenarios with follow-up turns; the fields above cover the whole chat)
+	Turns         []TurnResult `json:"turns,omitempty"`
+	FactRetention float64      `json:"fact_retention"` // share of expected facts kept across turns; set only with Turns
+
+	// Backend Accounting (token counts and timings reported by the runtime)
+	GenerationMetrics
+
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // BenchmarkSummary provides recommendations and insights
 type BenchmarkSummary struct {
-	OptimalModels      map[string]string `json:"optimal_models"`      // use_case -> model
-	MemoryRecommendations []string       `json:"memory_recommendations"`
-	PerformanceInsights   []string       `json:"performance_insights"`
-	CostEfficiencyScore   float64        `json:"cost_efficiency_score"`
-	RecommendedConfig     RecommendedConfig `json:"recommended_config"`
+	OptimalModels         map[string]string         `json:"optimal_models"`          // use_case -> model
+	TimeToFirstToken      map[string]time.Duration  `json:"time_to_first_token"`     // model -> average TTFT
+	Startup               map[string]StartupProfile `json:"startup"`                 // model -> load/cold/warm latency
+	Concurrency           *ConcurrencySweep         `json:"concurrency,omitempty"`   // only with --concurrency
+	ContextSweep          *ContextSweep             `json:"context_sweep,omitempty"` // only with --context-sweep
+	Quantization          []QuantizationComparison  `json:"quantization,omitempty"`  // families installed in several quantizations
+	MemoryRecommendations []string                  `json:"memory_recommendations"`
+	PerformanceInsights   []string                  `json:"performance_insights"`
+	CostEfficiencyScore   float64                   `json:"cost_efficiency_score"` // quality per joule per output token; 0 without energy readings
+	Energy                []EnergyCost              `json:"energy,omitempty"`      // electricity per model
+	KWhPrice              float64                   `json:"kwh_price"`
+	RecommendedConfig     RecommendedConfig         `json:"recommended_config"`
 }
 
 // RecommendedConfig provides optimal settings for this device
 type RecommendedConfig struct {
-	PrimaryModel       string            `json:"primary_model"`
-	FallbackModel      string            `json:"fallback_model"`
-	ContextLength      int               `json:"context_length"`
-	ConcurrentRequests int               `json:"concurrent_requests"`
-	MemoryBuffer       int               `json:"memory_buffer_mb"`
-	SwapRecommendation string            `json:"swap_recommendation"`
-	EnvironmentVars    map[string]string `json:"environment_vars"`
+	PrimaryModel        string            `json:"primary_model"`
+	FallbackModel       string            `json:"fallback_model"`
+	ContextLength       int               `json:"context_length"`
+	ConcurrentRequests  int               `json:"concurrent_requests"`
+	ConcurrencyMeasured bool              `json:"concurrency_measured"` // false when no --concurrency sweep ran and ConcurrentRequests is the default of 1
+	MemoryBuffer        int               `json:"memory_buffer_mb"`
+	SwapRecommendation  string            `json:"swap_recommendation"`
+	EnvironmentVars     map[string]string `json:"environment_vars"`
 }
 
 // TestScenario defines different testing scenarios for low-spec optimization
 type TestScenario struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	UseCase     string `json:"use_case"`
-	Prompt      string `json:"prompt"`
-	MaxTokens   int    `json:"max_tokens"`
-	Priority    string `json:"priority"` // "speed", "quality", "memory"
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	UseCase     string             `json:"use_case"`
+	Prompt      string             `json:"prompt"`
+	MaxTokens   int                `json:"max_tokens"`
+	Priority    string             `json:"priority"`          // "speed", "quality", "memory"
+	Options     GenerationOptions  `json:"options,omitempty"` // MaxTokens applies when Options.MaxTokens is unset
+	Turns       []ConversationTurn `json:"turns,omitempty"`   // follow-ups that turn the scenario into a chat
 }
 
 func main() {
+	opts, args, err := parseBackendOptions(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid options: %v", err)
+	}
+	modes, err := parseLowSpecOptions(args)
+	if err != nil {
+		log.Fatalf("Invalid options: %v", err)
+	}
+
 	fmt.Println("🔬 QRY Low-Spec AI Device Benchmark")
 	fmt.Println("===================================")
 	fmt.Println("Optimizing local AI for resource-constrained devices")
 	fmt.Println()
 
-	// Profile the current device
+	if modes.SnapshotDir != "" {
+		info, err := takeSnapshot("/", modes.SnapshotDir)
+		if err != nil {
+			log.Fatalf("Failed to take snapshot: %v", err)
+		}
+		fmt.Printf("📸 Captured %d files from %s to %s\n", info.Files, info.DeviceName, modes.SnapshotDir)
+		fmt.Printf("   Profile it offline with: --root=%s --mock (or --replay=<cassette recorded there>)\n", modes.SnapshotDir)
+		return
+	}
+
+	if modes.HostsFile != "" {
+		runMultiHostBenchmark(opts, modes)
+		return
+	}
+
+	// Profile the current device, or the one captured in a snapshot. A
+	// snapshot's device isn't this machine, so its resources aren't sampled.
 	fmt.Println("📊 Profiling device hardware...")
-	profile, err := profileHardware()
+	root := "/"
+	if modes.Root != "" {
+		root = modes.Root
+		defer disableLocalProbes()()
+	}
+	profile, err := profileHardware(root)
 	if err != nil {
 		log.Fatalf("Failed to profile hardware: %v", err)
 	}
 
 	printHardwareProfile(profile)
 
+	backend, finish, err := opts.apply(newDefaultBackend())
+	if err != nil {
+		log.Fatalf("Failed to set up backend: %v", err)
+	}
+
+	benchmark, err := runBenchmark(backend, profile, modes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := finish(); err != nil {
+		log.Printf("Failed to save cassette: %v", err)
+	} else if opts.Record != "" {
+		fmt.Printf("📼 Cassette saved to: %s\n", opts.Record)
+	}
+
+	// Save results
+	outputPath := fmt.Sprintf("results/low_spec_benchmark_%s_%s.json",
+		sanitizeDeviceName(profile.DeviceName),
+		time.Now().Format("2006-01-02_15-04-05"))
+
+	if err := saveBenchmark(benchmark, outputPath); err != nil {
+		log.Printf("Failed to save benchmark: %v", err)
+	} else {
+		fmt.Printf("💾 Results saved to: %s\n", outputPath)
+	}
+
+	// Print summary and recommendations
+	printLowSpecSummary(benchmark.Summary, profile)
+	printEmbeddingResults(benchmark.EmbeddingResults)
+
+	// Generate uroboro-compatible configuration
+	generateUroboroConfig(benchmark.Summary, profile)
+}
+
+// runBenchmark runs every enabled track against backend on the device
+// described by profile
+func runBenchmark(backend Backend, profile HardwareProfile, modes LowSpecOptions) (LowSpecBenchmark, error) {
+	// Energy falls back to a TDP estimate where counters can't be read
+	if modes.TDPWatts > 0 {
+		profile.TDPWatts = modes.TDPWatts
+	} else if profile.TDPWatts == 0 {
+		profile.TDPWatts = defaultTDPWatts(profile.ThermalProfile)
+	}
+
 	// Initialize benchmark
 	benchmark := LowSpecBenchmark{
 		DeviceProfile: profile,
@@ -123,12 +234,24 @@ func main() {
 	}
 
 	// Get available models
-	models := getAvailableModels()
-	if len(models) == 0 {
-		log.Fatal("No models available. Please install some models with: ollama pull <model>")
+	inventory := modelInventory(backend)
+	models, embeddingModels := getAvailableModels(backend, inventory)
+	if len(models) == 0 && len(embeddingModels) == 0 {
+		return benchmark, fmt.Errorf("no models available. Please install some models with: ollama pull <model>")
+	}
+
+	if modes.QuantizationOnly {
+		models = quantizationModels(inventory, models)
+		embeddingModels = nil
+		if len(models) == 0 {
+			return benchmark, fmt.Errorf("no model is installed in more than one quantization. Pull another variant, e.g.: ollama pull llama3.2:3b-instruct-q8_0")
+		}
 	}
 
 	fmt.Printf("🤖 Found %d models to test: %v\n", len(models), models)
+	if len(embeddingModels) > 0 {
+		fmt.Printf("🧬 Found %d embedding models: %v\n", len(embeddingModels), embeddingModels)
+	}
 
 	// Define test scenarios optimized for low-spec devices
 	scenarios := getLowSpecTestScenarios()
@@ -136,30 +259,40 @@ func main() {
 
 	// Run comprehensive benchmarks
 	fmt.Println("\n⚡ Starting low-spec optimization tests...")
-	benchmark.TestResults = runLowSpecBenchmarks(models, scenarios, profile)
+	benchmark.TestResults, benchmark.ColdStarts = runLowSpecBenchmarks(backend, inventory, models, scenarios, profile)
 
 	// Generate summary and recommendations
-	benchmark.Summary = generateLowSpecSummary(benchmark.TestResults, profile)
+	benchmark.Summary = generateLowSpecSummary(benchmark.TestResults, benchmark.ColdStarts, profile)
+	benchmark.Summary.KWhPrice = modes.KWhPrice
+	benchmark.Summary.Energy = summarizeEnergyCosts(benchmark.TestResults, modes.KWhPrice)
 
-	// Save results
-	outputPath := fmt.Sprintf("results/low_spec_benchmark_%s_%s.json",
-		sanitizeDeviceName(profile.DeviceName),
-		time.Now().Format("2006-01-02_15-04-05"))
+	// Measure how many parallel requests the primary model actually sustains
+	primaryModel := benchmark.Summary.RecommendedConfig.PrimaryModel
+	if modes.MaxConcurrency > 0 && primaryModel != "" {
+		benchmark.Summary.Concurrency = runConcurrencySweep(backend, primaryModel, scenarios[0], modes)
+		applyConcurrencyRecommendation(&benchmark.Summary.RecommendedConfig, benchmark.Summary.Concurrency)
+	}
 
-	if err := saveBenchmark(benchmark, outputPath); err != nil {
-		log.Printf("Failed to save benchmark: %v", err)
-	} else {
-		fmt.Printf("💾 Results saved to: %s\n", outputPath)
+	// Measure how large a context window fits this device
+	if modes.ContextSweep && primaryModel != "" {
+		info, _ := inventory.Lookup(primaryModel)
+		memoryLimit := profile.effectiveAvailableRAM() - int64(benchmark.Summary.RecommendedConfig.MemoryBuffer)
+		benchmark.Summary.ContextSweep = runContextSweep(backend, primaryModel, info, modes, memoryLimit)
+		applyContextRecommendation(&benchmark.Summary.RecommendedConfig, benchmark.Summary.ContextSweep)
 	}
 
-	// Print summary and recommendations
-	printLowSpecSummary(benchmark.Summary, profile)
+	// Embedding models get their own track
+	benchmark.EmbeddingResults = runEmbeddingBenchmarks(backend, embeddingModels)
+	if best := bestEmbeddingModel(benchmark.EmbeddingResults); best != "" {
+		benchmark.Summary.OptimalModels["embedding"] = best
+	}
 
-	// Generate uroboro-compatible configuration
-	generateUroboroConfig(benchmark.Summary, profile)
+	return benchmark, nil
 }
 
-func profileHardware() (HardwareProfile, error) {
+// profileHardware profiles the device whose /proc and /sys are under root:
+// "/" for this machine, or a directory written by the snapshot command
+func profileHardware(root string) (HardwareProfile, error) {
 	profile := HardwareProfile{
 		OS:           runtime.GOOS,
 		Architecture: runtime.GOARCH,
@@ -173,11 +306,31 @@ func profileHardware() (HardwareProfile, error) {
 		profile.DeviceName = "unknown"
 	}
 
+	// A snapshot records what can't be read back from its files
+	if root != "/" {
+		info, err := readSnapshotInfo(root)
+		if err != nil {
+			return profile, err
+		}
+		profile.DeviceName, profile.OS, profile.Architecture, profile.CPUCores = info.DeviceName, info.OS, info.Architecture, info.CPUCores
+	}
+	procRoot, sysRoot := filepath.Join(root, "proc"), filepath.Join(root, "sys")
+
 	// Get memory info
-	if runtime.GOOS == "linux" {
-		profile.TotalRAM, profile.AvailableRAM = getLinuxMemoryInfo()
-		profile.StorageType = getLinuxStorageType()
-	} else if runtime.GOOS == "darwin" {
+	if profile.OS == "linux" {
+		profile.TotalRAM, profile.AvailableRAM = getLinuxMemoryInfo(procRoot)
+		profile.StorageType = getLinuxStorageType(sysRoot)
+		if governor := readCPUGovernor(sysRoot); len(governor.Governors) > 0 {
+			profile.CPUGovernor = &governor
+		}
+		if power, ok := readPowerStatus(sysRoot); ok {
+			profile.Power = &power
+		}
+		profile.Cgroup = readCgroupLimits(procRoot, sysRoot)
+		if caps, ok := readCPUCapabilities(procRoot, sysRoot); ok {
+			profile.CPUCapabilities = &caps
+		}
+	} else if profile.OS == "darwin" {
 		profile.TotalRAM, profile.AvailableRAM = getMacMemoryInfo()
 		profile.StorageType = "SSD" // Most Macs have SSD
 	}
@@ -189,9 +342,9 @@ func profileHardware() (HardwareProfile, error) {
 	return profile, nil
 }
 
-func getLinuxMemoryInfo() (int64, int64) {
+func getLinuxMemoryInfo(procRoot string) (int64, int64) {
 	// Read /proc/meminfo
-	data, err := os.ReadFile("/proc/meminfo")
+	data, err := os.ReadFile(filepath.Join(procRoot, "meminfo"))
 	if err != nil {
 		return 0, 0
 	}
@@ -238,21 +391,28 @@ func getMacMemoryInfo() (int64, int64) {
 	return 0, 0
 }
 
-func getLinuxStorageType() string {
-	// Check if main storage is SSD
-	cmd := exec.Command("lsblk", "-d", "-o", "name,rota")
-	output, err := cmd.Output()
-	if err != nil {
-		return "unknown"
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines[1:] { // Skip header
-		if strings.Contains(line, "0") { // ROTA=0 means SSD
+// getLinuxStorageType reports SSD when any disk under sysRoot is
+// non-rotational. Loop, RAM and device-mapper devices aren't disks and are
+// skipped.
+func getLinuxStorageType(sysRoot string) string {
+	devices, _ := filepath.Glob(filepath.Join(sysRoot, "block", "*", "queue", "rotational"))
+	storageType := "unknown"
+	for _, path := range devices {
+		name := filepath.Base(filepath.Dir(filepath.Dir(path)))
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram") ||
+			strings.HasPrefix(name, "dm-") || strings.HasPrefix(name, "sr") {
+			continue
+		}
+		rotational, ok := readSysInt(path)
+		if !ok {
+			continue
+		}
+		if rotational == 0 {
 			return "SSD"
 		}
+		storageType = "HDD"
 	}
-	return "HDD"
+	return storageType
 }
 
 func inferThermalProfile(profile HardwareProfile) string {
@@ -269,38 +429,32 @@ func inferThermalProfile(profile HardwareProfile) string {
 }
 
 func inferPowerProfile(profile HardwareProfile) string {
-	// Assume battery for mobile/laptop, plugged for others
+	if profile.Power != nil {
+		return profile.Power.powerProfile()
+	}
+
+	// Without power_supply readings, assume battery for mobile/laptop, plugged for others
 	if profile.ThermalProfile == "mobile" || profile.ThermalProfile == "laptop" {
 		return "battery"
 	}
 	return "plugged"
 }
 
-func getAvailableModels() []string {
-	cmd := exec.Command("ollama", "list")
-	output, err := cmd.Output()
-	if err != nil {
-		return []string{}
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var models []string
-
-	for _, line := range lines[1:] { // Skip header
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		parts := strings.Fields(line)
-		if len(parts) > 0 {
-			modelName := parts[0]
-			// Filter out problematic models for low-spec testing
-			if !strings.Contains(modelName, "embedding") {
-				models = append(models, modelName)
-			}
+// getAvailableModels splits the installed models into generation models and
+// embedding models, which run on their own track
+func getAvailableModels(backend Backend, inventory *ModelInventory) ([]string, []string) {
+	var models, embeddingModels []string
+
+	for _, modelName := range listBackendModels(backend) {
+		info, _ := inventory.Lookup(modelName)
+		if isEmbeddingModel(modelName, info) {
+			embeddingModels = append(embeddingModels, modelName)
+		} else {
+			models = append(models, modelName)
 		}
 	}
 
-	return models
+	return models, embeddingModels
 }
 
 func getLowSpecTestScenarios() []TestScenario {
@@ -337,6 +491,18 @@ func getLowSpecTestScenarios() []TestScenario {
 			MaxTokens:   400,
 			Prompt:      "Write technical development log: Migrated from monolith to microservices. Challenges: data consistency, service discovery, monitoring. Solutions: event sourcing, Consul, Prometheus",
 		},
+		{
+			Name:        "Conversational Refinement",
+			Description: "Iterative devlog editing, the way uroboro refines drafts",
+			UseCase:     "devlog",
+			Priority:    "quality",
+			MaxTokens:   250,
+			Prompt:      "Write a short devlog entry: Replaced polling with webhooks for payment status, cutting API calls by 90% and confirmation time from 30s to 2s",
+			Turns: []ConversationTurn{
+				{User: "Make it shorter, three bullet points at most.", Expect: []string{"webhook"}},
+				{User: "Add the metrics back in if you dropped any.", Expect: []string{"90%", "2s"}},
+			},
+		},
 		{
 			Name:        "Resource-Constrained Blog",
 			Description: "Blog post generation for very low-spec devices",
@@ -348,95 +514,193 @@ func getLowSpecTestScenarios() []TestScenario {
 	}
 }
 
-func runLowSpecBenchmarks(models []string, scenarios []TestScenario, profile HardwareProfile) []BenchmarkResult {
-	var results []BenchmarkResult
+// recoveryPause is how long the device rests between tests
+var recoveryPause = 2 * time.Second
+
+// runLowSpecBenchmarks returns the warm results and, where the backend can
+// unload models, one cold-start result per model
+func runLowSpecBenchmarks(backend Backend, inventory *ModelInventory, models []string, scenarios []TestScenario, profile HardwareProfile) ([]BenchmarkResult, []BenchmarkResult) {
+	var results, coldStarts []BenchmarkResult
 	total := len(models) * len(scenarios)
 	current := 0
 
 	for _, model := range models {
 		fmt.Printf("\n🧪 Testing %s on %s (%s, %dMB RAM)\n",
-			model, profile.DeviceName, profile.ThermalProfile, profile.AvailableRAM)
+			model, profile.DeviceName, profile.ThermalProfile, profile.effectiveAvailableRAM())
+		info, _ := inventory.Lookup(model)
+
+		// Cold phase: evict the model so load time is measured on its own
+		if len(scenarios) > 0 {
+			if supported, err := unloadModel(backend, model); !supported {
+				fmt.Println("  🧊 Cold start: skipped (backend can't unload models)")
+			} else if err != nil {
+				fmt.Printf("  🧊 Cold start: skipped (unload failed: %v)\n", err)
+			} else {
+				cold := runLowSpecTest(backend, model, info, scenarios[0], profile)
+				cold.Phase = "cold"
+				coldStarts = append(coldStarts, cold)
+				if cold.Success {
+					fmt.Printf("  🧊 Cold start: %v (load %v)\n",
+						cold.ResponseTime.Round(time.Millisecond),
+						cold.LoadDuration.Round(time.Millisecond))
+				} else {
+					fmt.Printf("  🧊 Cold start: ❌ %s\n", cold.Error)
+				}
+			}
+		}
 
+		// Warm phase: the model stays loaded between scenarios
 		for _, scenario := range scenarios {
 			current++
 			fmt.Printf("  📝 %s [%d/%d] ", scenario.Name, current, total)
 
-			result := runLowSpecTest(model, scenario, profile)
+			result := runLowSpecTest(backend, model, info, scenario, profile)
 			results = append(results, result)
 
 			if result.Success {
-				fmt.Printf("✅ %v (%.1f t/s, %dMB)\n",
+				fmt.Printf("✅ %v (TTFT %v, %.1f t/s, %dMB)\n",
 					result.ResponseTime.Round(time.Millisecond),
+					result.TimeToFirstToken.Round(time.Millisecond),
 					result.TokensPerSecond,
 					result.PeakMemoryMB)
+				if memory := result.ProcessMemory; memory != nil {
+					fmt.Printf("     runtime memory (%s): peak %dMB, mean %.0fMB over %d samples\n",
+						memory.Source, memory.PeakMB, memory.MeanMB, len(memory.Samples))
+				}
+				if result.CPU != nil {
+					fmt.Printf("     %s\n", formatCPUUsage(result.CPU))
+				}
+				if result.MemoryPressure != nil {
+					fmt.Printf("     %s\n", formatMemoryPressure(result.MemoryPressure))
+				}
+				if result.Thermal != nil {
+					if summary := formatThermalReading(result.Thermal); summary != "" {
+						fmt.Printf("     %s\n", summary)
+					}
+				}
+				if result.Power != nil {
+					if summary := formatPowerUsage(result.Power); summary != "" {
+						fmt.Printf("     %s\n", summary)
+					}
+				}
+				if len(result.Turns) > 0 {
+					fmt.Printf("     %d turns: %s, facts kept %.0f%%\n", len(result.Turns),
+						formatTurnLatency(turnLatencies(result.Turns)), result.FactRetention*100)
+				}
 			} else {
 				fmt.Printf("❌ %s\n", result.Error)
 			}
 
 			// Brief pause to let system recover
-			time.Sleep(2 * time.Second)
+			time.Sleep(recoveryPause)
 		}
 	}
 
-	return results
+	return results, coldStarts
 }
 
-func runLowSpecTest(model string, scenario TestScenario, profile HardwareProfile) BenchmarkResult {
+func runLowSpecTest(backend Backend, model string, info ModelInfo, scenario TestScenario, profile HardwareProfile) BenchmarkResult {
 	result := BenchmarkResult{
 		Model:     model,
+		Phase:     "warm",
+		ModelInfo: info,
 		TestCase:  scenario.Name,
 		Prompt:    scenario.Prompt,
+		Options:   scenario.Options.withDefaults(GenerationOptions{MaxTokens: scenario.MaxTokens}),
 		Timestamp: time.Now(),
 	}
 
-	// Infer model size from name
-	result.ModelSize = inferModelSize(model)
-	result.Quantization = inferQuantization(model)
+	// Prefer the runtime's metadata; names are only a fallback for backends
+	// that don't report it
+	if info.Name != "" {
+		result.ModelSize = info.SizeLabel()
+		result.Quantization = info.QuantizationLevel
+	} else {
+		result.ModelSize = inferModelSize(model)
+		result.Quantization = inferQuantization(model)
+	}
 
-	// Capture initial system state
-	initialMemory := getCurrentMemoryUsage()
+	// Sample the runtime and the device for the whole request
+	memoryGrowth := sampleMemoryGrowth()
+	processMemory := sampleProcessMemory()
+	cpuUsage := sampleCPUUsage()
+	memoryPressure := measureMemoryPressure()
+	thermal := sampleThermal()
+	power := measurePower()
+	energy := measureEnergy()
 
 	// Run the test
 	start := time.Now()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	// Each turn of a conversation gets the single-prompt budget
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(1+len(scenario.Turns))*60*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "ollama", "run", model)
-	cmd.Stdin = strings.NewReader(scenario.Prompt)
-
-	output, err := cmd.Output()
+	var resp GenerateResponse
+	var err error
+	if len(scenario.Turns) > 0 {
+		score := func(output string) float64 { return assessOutputQuality(output, scenario.UseCase) }
+		result.Turns, resp, err = runConversation(ctx, backend, model, scenario.Prompt, scenario.Turns, result.Options, score)
+	} else {
+		resp, err = backend.Generate(ctx, GenerateRequest{Model: model, Prompt: scenario.Prompt, Options: result.Options})
+	}
 	responseTime := time.Since(start)
 
-	// Capture final system state
-	finalMemory := getCurrentMemoryUsage()
+	result.ProcessMemory = processMemory()
+	if result.CPU = cpuUsage(); result.CPU != nil {
+		result.AvgCPUPercent = result.CPU.AvgPercent
+	}
+	result.MemoryPressure = memoryPressure()
+	result.Thermal = thermal()
+	result.Power = power(responseTime)
+	if result.Energy = energy(responseTime); result.Energy == nil {
+		result.Energy = estimateEnergy(responseTime, result.CPU, profile.TDPWatts)
+	}
+	result.PeakMemoryMB = memoryGrowth()
+	if result.ProcessMemory != nil {
+		result.PeakMemoryMB = result.ProcessMemory.PeakMB
+	}
 
 	result.ResponseTime = responseTime
-	result.PeakMemoryMB = finalMemory - initialMemory
+	result.Backend = resp.Backend
 
 	if err != nil {
 		result.Success = false
 		result.Error = err.Error()
-		result.OOMRisk = assessOOMRisk(result.PeakMemoryMB, profile.AvailableRAM)
+		result.OOMRisk = assessOOMRisk(result.MemoryPressure, result.PeakMemoryMB, profile.effectiveAvailableRAM())
 		return result
 	}
 
 	result.Success = true
-	result.Output = strings.TrimSpace(string(output))
+	result.Output = resp.Output
 	result.OutputLength = len(result.Output)
+	if len(result.Turns) > 0 {
+		result.FactRetention = factRetention(result.Turns)
+	}
 
-	// Calculate performance metrics
-	if result.OutputLength > 0 && responseTime > 0 {
-		// Rough token estimation (4 chars per token average)
-		estimatedTokens := float64(result.OutputLength) / 4.0
-		result.TokensPerSecond = estimatedTokens / responseTime.Seconds()
+	// Performance metrics come from the backend's own token accounting
+	result.GenerationMetrics = resp.Metrics
+	result.TokensPerSecond = resp.Metrics.DecodeRate()
+	result.TimeToFirstToken = resp.Timing.TimeToFirstToken
+	result.InterTokenLatency = resp.Timing.InterTokenLatency
+	result.DecodeTimeline = resp.Timing.DecodeTimeline
+	if result.Energy != nil && result.EvalCount > 0 {
+		result.Energy.JoulesPerToken = result.Energy.Joules / float64(result.EvalCount)
 	}
 
 	// Assess low-spec specific metrics
-	result.OOMRisk = assessOOMRisk(result.PeakMemoryMB, profile.AvailableRAM)
-	result.BatteryImpact = assessBatteryImpact(responseTime, result.PeakMemoryMB, profile.PowerProfile)
-	result.SwapUsed = result.PeakMemoryMB > profile.AvailableRAM
-	result.ThermalThrottling = assessThermalThrottling(responseTime, profile.ThermalProfile)
+	result.OOMRisk = assessOOMRisk(result.MemoryPressure, result.PeakMemoryMB, profile.effectiveAvailableRAM())
+	result.BatteryImpact = assessBatteryImpact(responseTime, result.PeakMemoryMB, result.Power, profile.PowerProfile)
+	if result.MemoryPressure != nil {
+		result.SwapUsed = result.MemoryPressure.Swapped()
+	} else {
+		result.SwapUsed = result.PeakMemoryMB > profile.effectiveAvailableRAM()
+	}
+	if result.Thermal != nil {
+		result.ThermalThrottling = result.Thermal.ThrottleEvents > 0
+	} else {
+		result.ThermalThrottling = assessThermalThrottling(responseTime, profile.ThermalProfile)
+	}
 
 	// Calculate efficiency scores
 	if result.PeakMemoryMB > 0 {
@@ -444,43 +708,72 @@ func runLowSpecTest(model string, scenario TestScenario, profile HardwareProfile
 	}
 
 	result.QualityScore = assessOutputQuality(result.Output, scenario.UseCase)
+	if len(result.Turns) > 0 {
+		// Forgetting earlier facts makes the final reply less useful
+		result.QualityScore *= result.FactRetention
+	}
 	result.UsabilityScore = calculateUsabilityScore(responseTime, result.QualityScore, scenario.Priority)
 
 	return result
 }
 
-func getCurrentMemoryUsage() int64 {
+// memoryProbe reads available RAM in MB. Multi-host runs replace it while
+// benchmarking a remote host, whose memory can't be read from here.
+var memoryProbe = func() int64 { return getCurrentMemoryUsage("/proc") }
+
+func getCurrentMemoryUsage(procRoot string) int64 {
 	if runtime.GOOS == "linux" {
-		data, err := os.ReadFile("/proc/meminfo")
-		if err != nil {
-			return 0
-		}
+		_, available := getLinuxMemoryInfo(procRoot)
+		return available
+	}
+	return 0
+}
 
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "MemAvailable:") {
-				parts := strings.Fields(line)
-				if len(parts) >= 2 {
-					if val, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
-						return val / 1024 // Convert KB to MB
-					}
+// sampleMemoryGrowth polls available RAM in the background. The returned func
+// stops polling and reports how far it dropped below the starting value, in MB.
+func sampleMemoryGrowth() func() int64 {
+	initialMemory := memoryProbe()
+	lowestMemory := initialMemory
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if available := memoryProbe(); available > 0 && available < lowestMemory {
+					lowestMemory = available
 				}
 			}
 		}
+	}()
+
+	return func() int64 {
+		close(stop)
+		<-done
+		if initialMemory > 0 && lowestMemory < initialMemory {
+			return initialMemory - lowestMemory
+		}
+		return 0
 	}
-	return 0
 }
 
 func inferModelSize(model string) string {
 	model = strings.ToLower(model)
-	if strings.Contains(model, "3b") {
-		return "3b"
-	} else if strings.Contains(model, "7b") {
-		return "7b"
+	// Check larger sizes first: "13b" also contains "3b"
+	if strings.Contains(model, "30b") || strings.Contains(model, "33b") {
+		return "30b+"
 	} else if strings.Contains(model, "13b") {
 		return "13b"
-	} else if strings.Contains(model, "30b") || strings.Contains(model, "33b") {
-		return "30b+"
+	} else if strings.Contains(model, "7b") {
+		return "7b"
+	} else if strings.Contains(model, "3b") {
+		return "3b"
 	}
 	return "unknown"
 }
@@ -494,10 +787,15 @@ func inferQuantization(model string) string {
 	} else if strings.Contains(model, "fp16") {
 		return "fp16"
 	}
-	return "fp16" // Default assumption
+	return "unknown"
 }
 
-func assessOOMRisk(memoryUsed, availableMemory int64) string {
+// assessOOMRisk rates the pressure observed during the run. The share of
+// available RAM used is only a fallback where the kernel counters can't be read.
+func assessOOMRisk(pressure *MemoryPressure, memoryUsed, availableMemory int64) string {
+	if pressure != nil {
+		return pressure.OOMRisk()
+	}
 	if memoryUsed <= 0 || availableMemory <= 0 {
 		return "unknown"
 	}
@@ -511,8 +809,23 @@ func assessOOMRisk(memoryUsed, availableMemory int64) string {
 	return "high"
 }
 
-func assessBatteryImpact(responseTime time.Duration, memoryUsed int64, powerProfile string) string {
-	if powerProfile != "battery" {
+// assessBatteryImpact rates the energy a run drew from the battery. Without
+// a measured discharge rate it falls back to a guess from time and memory.
+func assessBatteryImpact(responseTime time.Duration, memoryUsed int64, power *PowerUsage, powerProfile string) string {
+	if power != nil {
+		if !power.Before.OnBattery && !power.After.OnBattery {
+			return "n/a"
+		}
+		if power.EstimatedWh > 0 {
+			switch {
+			case power.EstimatedWh < 0.05:
+				return "minimal"
+			case power.EstimatedWh < 0.25:
+				return "moderate"
+			}
+			return "high"
+		}
+	} else if powerProfile != "battery" {
 		return "n/a"
 	}
 
@@ -527,6 +840,8 @@ func assessBatteryImpact(responseTime time.Duration, memoryUsed int64, powerProf
 	return "high"
 }
 
+// assessThermalThrottling guesses from response time alone; it only stands in
+// where neither thermal zones nor cpufreq can be read
 func assessThermalThrottling(responseTime time.Duration, thermalProfile string) bool {
 	// Heuristic: if response time is unusually long for thermal profile
 	var expectedTime time.Duration
@@ -598,9 +913,12 @@ func calculateUsabilityScore(responseTime time.Duration, qualityScore float64, p
 	}
 }
 
-func generateLowSpecSummary(results []BenchmarkResult, profile HardwareProfile) BenchmarkSummary {
+func generateLowSpecSummary(results []BenchmarkResult, coldStarts []BenchmarkResult, profile HardwareProfile) BenchmarkSummary {
 	summary := BenchmarkSummary{
-		OptimalModels: make(map[string]string),
+		OptimalModels:    make(map[string]string),
+		TimeToFirstToken: averageTimeToFirstToken(results),
+		Startup:          buildStartupProfiles(results, coldStarts),
+		Quantization:     compareQuantizations(results),
 	}
 
 	// Group results by use case
@@ -623,12 +941,113 @@ func generateLowSpecSummary(results []BenchmarkResult, profile HardwareProfile)
 	// Generate recommendations
 	summary.MemoryRecommendations = generateMemoryRecommendations(results, profile)
 	summary.PerformanceInsights = generatePerformanceInsights(results, profile)
+	summary.PerformanceInsights = append(summary.PerformanceInsights, generateStartupInsights(summary.Startup)...)
+	summary.PerformanceInsights = append(summary.PerformanceInsights, generateQuantizationInsights(summary.Quantization)...)
+	summary.PerformanceInsights = append(summary.PerformanceInsights, generateConversationInsights(results)...)
 	summary.CostEfficiencyScore = calculateCostEfficiencyScore(results, profile)
 	summary.RecommendedConfig = generateRecommendedConfig(results, profile)
+	applyStartupRecommendations(&summary.RecommendedConfig, summary.Startup)
 
 	return summary
 }
 
+func averageTimeToFirstToken(results []BenchmarkResult) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	counts := make(map[string]int)
+
+	for _, result := range results {
+		if result.Success && result.TimeToFirstToken > 0 {
+			totals[result.Model] += result.TimeToFirstToken
+			counts[result.Model]++
+		}
+	}
+
+	averages := make(map[string]time.Duration)
+	for model, total := range totals {
+		averages[model] = total / time.Duration(counts[model])
+	}
+	return averages
+}
+
+// buildStartupProfiles pairs each model's cold start with its warm average
+func buildStartupProfiles(results []BenchmarkResult, coldStarts []BenchmarkResult) map[string]StartupProfile {
+	profiles := make(map[string]StartupProfile)
+
+	warmTotals := make(map[string]time.Duration)
+	warmCounts := make(map[string]int)
+	for _, result := range results {
+		if result.Success {
+			warmTotals[result.Model] += result.ResponseTime
+			warmCounts[result.Model]++
+		}
+	}
+
+	for _, cold := range coldStarts {
+		if !cold.Success {
+			continue
+		}
+		startup := StartupProfile{
+			LoadTime:    cold.LoadDuration,
+			ColdLatency: cold.ResponseTime,
+		}
+		if warmCounts[cold.Model] > 0 {
+			startup.WarmLatency = warmTotals[cold.Model] / time.Duration(warmCounts[cold.Model])
+		}
+		profiles[cold.Model] = startup
+	}
+
+	return profiles
+}
+
+func generateStartupInsights(startup map[string]StartupProfile) []string {
+	var insights []string
+
+	for model, profile := range startup {
+		if profile.LoadTime > 3*time.Second {
+			insights = append(insights, fmt.Sprintf("%s takes %v to load - every capture after it is evicted pays this cost",
+				model, profile.LoadTime.Round(100*time.Millisecond)))
+		}
+	}
+
+	return insights
+}
+
+// generateConversationInsights reports, per model, how well facts survive a
+// multi-turn chat and how latency grows as the history does
+func generateConversationInsights(results []BenchmarkResult) []string {
+	conversations := make(map[string][][]TurnResult)
+	retention := make(map[string]float64)
+	var models []string
+
+	for _, result := range results {
+		if len(result.Turns) == 0 {
+			continue
+		}
+		if _, ok := conversations[result.Model]; !ok {
+			models = append(models, result.Model)
+		}
+		conversations[result.Model] = append(conversations[result.Model], result.Turns)
+		if result.Success {
+			retention[result.Model] += result.FactRetention
+		}
+	}
+
+	var insights []string
+	for _, model := range models {
+		insights = append(insights, fmt.Sprintf("%s keeps %.0f%% of earlier facts across turns; per-turn latency %s",
+			model, retention[model]/float64(len(conversations[model]))*100,
+			formatTurnLatency(averageTurnLatency(conversations[model]))))
+	}
+	return insights
+}
+
+// applyStartupRecommendations keeps slow-loading primary models resident
+func applyStartupRecommendations(config *RecommendedConfig, startup map[string]StartupProfile) {
+	if profile, ok := startup[config.PrimaryModel]; ok && profile.LoadTime > 2*time.Second {
+		config.EnvironmentVars["OLLAMA_KEEP_ALIVE"] = "30m"
+	}
+}
+
 func findOptimalModelForUseCase(results []BenchmarkResult, profile HardwareProfile) string {
 	if len(results) == 0 {
 		return ""
@@ -641,7 +1060,7 @@ func findOptimalModelForUseCase(results []BenchmarkResult, profile HardwareProfi
 		score := result.UsabilityScore
 
 		// Penalize high memory usage on low-spec devices
-		if profile.AvailableRAM < 8192 && result.OOMRisk == "high" {
+		if profile.effectiveAvailableRAM() < 8192 && result.OOMRisk == "high" {
 			score -= 2.0
 		}
 
@@ -667,11 +1086,11 @@ func findOptimalModelForUseCase(results []BenchmarkResult, profile HardwareProfi
 func generateMemoryRecommendations(results []BenchmarkResult, profile HardwareProfile) []string {
 	var recommendations []string
 
-	if profile.AvailableRAM < 4096 {
+	if profile.effectiveAvailableRAM() < 4096 {
 		recommendations = append(recommendations, "Consider using 3B models only for this device")
 		recommendations = append(recommendations, "Enable swap file (4GB minimum) for stability")
 		recommendations = append(recommendations, "Close other applications during AI processing")
-	} else if profile.AvailableRAM < 8192 {
+	} else if profile.effectiveAvailableRAM() < 8192 {
 		recommendations = append(recommendations, "7B models should work well with careful memory management")
 		recommendations = append(recommendations, "Monitor memory usage and consider swap for larger models")
 	} else {
@@ -679,6 +1098,13 @@ func generateMemoryRecommendations(results []BenchmarkResult, profile HardwarePr
 		recommendations = append(recommendations, "Consider running multiple models for different use cases")
 	}
 
+	if profile.effectiveAvailableRAM() < profile.AvailableRAM {
+		recommendations = append(recommendations, fmt.Sprintf("Container memory limit leaves %dMB of the host's %dMB available - raise it to run larger models",
+			profile.effectiveAvailableRAM(), profile.AvailableRAM))
+	}
+
+	recommendations = append(recommendations, generatePressureRecommendations(results)...)
+
 	return recommendations
 }
 
@@ -707,6 +1133,17 @@ func generatePerformanceInsights(results []BenchmarkResult, profile HardwareProf
 
 	insights = append(insights, fmt.Sprintf("Average response time: %v", avgTime.Round(time.Millisecond)))
 
+	// Time to first token is what an interactive capture actually feels like
+	var totalTTFT time.Duration
+	for _, result := range successfulResults {
+		totalTTFT += result.TimeToFirstToken
+	}
+	avgTTFT := totalTTFT / time.Duration(len(successfulResults))
+	insights = append(insights, fmt.Sprintf("Average time to first token: %v", avgTTFT.Round(time.Millisecond)))
+	if avgTTFT > 2*time.Second {
+		insights = append(insights, "Time to first token exceeds 2s - captures will feel sluggish; prefer a smaller model or keep it loaded")
+	}
+
 	// Thermal assessment
 	throttlingCount := 0
 	for _, result := range successfulResults {
@@ -719,18 +1156,32 @@ func generatePerformanceInsights(results []BenchmarkResult, profile HardwareProf
 		insights = append(insights, fmt.Sprintf("Thermal throttling detected in %d/%d tests", throttlingCount, len(successfulResults)))
 	}
 
+	insights = append(insights, generateThermalInsights(successfulResults)...)
+	if profile.CPUGovernor != nil {
+		if warning := governorWarning(*profile.CPUGovernor); warning != "" {
+			insights = append(insights, warning)
+		}
+	}
+	if profile.CPUCapabilities != nil {
+		insights = append(insights, generateCPUCapabilityInsights(*profile.CPUCapabilities)...)
+	}
+	insights = append(insights, generateCPUInsights(successfulResults)...)
+
 	return insights
 }
 
+// calculateCostEfficiencyScore averages quality per joule of output tokens,
+// i.e. quality x tokens per joule, over the runs with an energy reading.
+// Measured and TDP-estimated readings both count; runs without one (remote
+// hosts, backends that don't count tokens) are left out rather than scored
+// in other units. 0 means no run could be scored.
 func calculateCostEfficiencyScore(results []BenchmarkResult, profile HardwareProfile) float64 {
-	// Calculate cost efficiency as quality/resource_usage
 	var totalScore float64
 	var count int
 
 	for _, result := range results {
-		if result.Success && result.PeakMemoryMB > 0 {
-			efficiency := (result.QualityScore * result.TokensPerSecond) / float64(result.PeakMemoryMB)
-			totalScore += efficiency
+		if result.Success && result.Energy != nil && result.Energy.JoulesPerToken > 0 {
+			totalScore += result.QualityScore / result.Energy.JoulesPerToken
 			count++
 		}
 	}
@@ -761,21 +1212,21 @@ func generateRecommendedConfig(results []BenchmarkResult, profile HardwareProfil
 	config.PrimaryModel = bestModel
 
 	// Memory management settings
-	if profile.AvailableRAM < 8192 {
+	if profile.effectiveAvailableRAM() < 8192 {
 		config.ContextLength = 2048
-		config.ConcurrentRequests = 1
 		config.MemoryBuffer = 1024
 		config.SwapRecommendation = "Enable 4GB swap file"
 	} else {
 		config.ContextLength = 4096
-		config.ConcurrentRequests = 2
 		config.MemoryBuffer = 2048
 		config.SwapRecommendation = "Optional"
 	}
+	// Parallel requests only pay off where a concurrency sweep shows it
+	config.ConcurrentRequests = 1
 
 	// Environment variables for optimization
 	config.EnvironmentVars["OLLAMA_MAX_LOADED_MODELS"] = "1"
-	config.EnvironmentVars["OLLAMA_NUM_PARALLEL"] = "1"
+	config.EnvironmentVars["OLLAMA_NUM_PARALLEL"] = strconv.Itoa(config.ConcurrentRequests)
 
 	if profile.ThermalProfile == "mobile" || profile.ThermalProfile == "laptop" {
 		config.EnvironmentVars["OLLAMA_FLASH_ATTENTION"] = "1"
@@ -802,10 +1253,25 @@ func printHardwareProfile(profile HardwareProfile) {
 	fmt.Printf("📱 Device: %s\n", profile.DeviceName)
 	fmt.Printf("💻 OS: %s/%s\n", profile.OS, profile.Architecture)
 	fmt.Printf("🔧 CPU Cores: %d\n", profile.CPUCores)
+	if profile.CPUCapabilities != nil {
+		fmt.Printf("🧮 CPU: %s\n", formatCPUCapabilities(*profile.CPUCapabilities))
+	}
 	fmt.Printf("💾 RAM: %dMB total, %dMB available\n", profile.TotalRAM, profile.AvailableRAM)
+	if profile.Cgroup != nil {
+		fmt.Printf("📦 Container: %s\n", formatCgroupLimits(profile.Cgroup))
+	}
 	fmt.Printf("💽 Storage: %s\n", profile.StorageType)
 	fmt.Printf("🌡️  Thermal Profile: %s\n", profile.ThermalProfile)
 	fmt.Printf("🔋 Power Profile: %s\n", profile.PowerProfile)
+	if profile.Power != nil && profile.Power.Battery {
+		fmt.Printf("🪫 Battery: %.0f%% (%s)\n", profile.Power.ChargePercent, profile.Power.Status)
+	}
+	if profile.CPUGovernor != nil {
+		fmt.Printf("⏱️  CPU Governor: %s (%s)\n", strings.Join(profile.CPUGovernor.Governors, ", "), profile.CPUGovernor.Driver)
+		if warning := governorWarning(*profile.CPUGovernor); warning != "" {
+			fmt.Printf("⚠️  %s\n", warning)
+		}
+	}
 	fmt.Println()
 }
 
@@ -818,6 +1284,66 @@ func printLowSpecSummary(summary BenchmarkSummary, profile HardwareProfile) {
 		fmt.Printf("  %s: %s\n", useCase, model)
 	}
 
+	if len(summary.TimeToFirstToken) > 0 {
+		fmt.Println("\n⏱️  Time to First Token:")
+		for model, ttft := range summary.TimeToFirstToken {
+			fmt.Printf("  %s: %v\n", model, ttft.Round(time.Millisecond))
+		}
+	}
+
+	if sweep := summary.Concurrency; sweep != nil {
+		fmt.Printf("\n🔀 Concurrency (%s, p90 budget %v):\n", sweep.Model, sweep.LatencyBudget)
+		for _, level := range sweep.Levels {
+			fmt.Printf("  x%d: %.1f t/s aggregate, p50 %v, p90 %v, p99 %v, +%dMB\n",
+				level.Concurrency, level.AggregateTokensPerSecond,
+				level.Latency.P50.Round(time.Millisecond),
+				level.Latency.P90.Round(time.Millisecond),
+				level.Latency.P99.Round(time.Millisecond),
+				level.MemoryGrowthMB)
+		}
+		fmt.Printf("  → %d parallel: %s\n", sweep.Recommended, sweep.Reason)
+	}
+
+	if sweep := summary.ContextSweep; sweep != nil {
+		fmt.Printf("\n📏 Context Length (%s, %dMB limit, budget %v):\n", sweep.Model, sweep.MemoryLimitMB, sweep.LatencyBudget)
+		for _, step := range sweep.Steps {
+			if !step.Success {
+				fmt.Printf("  num_ctx %d: ❌ %s\n", step.NumCtx, step.Error)
+				continue
+			}
+			fmt.Printf("  num_ctx %d: %v (+%v load), prefill %.1f t/s, decode %.1f t/s, +%dMB\n",
+				step.NumCtx, step.ServingTime.Round(time.Millisecond), step.LoadTime.Round(time.Millisecond),
+				step.PrefillRate, step.DecodeRate, step.MemoryMB)
+		}
+		if sweep.Recommended > 0 {
+			fmt.Printf("  → %d tokens: %s\n", sweep.Recommended, sweep.Reason)
+		} else {
+			fmt.Printf("  → %s\n", sweep.Reason)
+		}
+	}
+
+	for _, comparison := range summary.Quantization {
+		fmt.Printf("\n🗜️  Quantization: %s %s (vs %s):\n", comparison.Family, comparison.ParameterSize, comparison.Reference)
+		for _, variant := range comparison.Variants {
+			fmt.Printf("  %s (%s): quality %.2f (%+.2f), %.1f t/s (%+.0f%%), peak %dMB (%+.0f%%), %dMB on disk (%+.0f%%)\n",
+				variant.Model, variant.Quantization,
+				variant.QualityScore, variant.QualityDelta,
+				variant.TokensPerSecond, variant.SpeedDelta,
+				variant.PeakMemoryMB, variant.MemoryDelta,
+				variant.SizeMB, variant.SizeDelta)
+		}
+	}
+
+	if len(summary.Startup) > 0 {
+		fmt.Println("\n🧊 Cold Start vs Warm:")
+		for model, startup := range summary.Startup {
+			fmt.Printf("  %s: load %v, cold %v, warm %v\n", model,
+				startup.LoadTime.Round(time.Millisecond),
+				startup.ColdLatency.Round(time.Millisecond),
+				startup.WarmLatency.Round(time.Millisecond))
+		}
+	}
+
 	fmt.Println("\n💾 Memory Recommendations:")
 	for _, rec := range summary.MemoryRecommendations {
 		fmt.Printf("  • %s\n", rec)
@@ -828,7 +1354,12 @@ func printLowSpecSummary(summary BenchmarkSummary, profile HardwareProfile) {
 		fmt.Printf("  • %s\n", insight)
 	}
 
-	fmt.Printf("\n💰 Cost Efficiency Score: %.2f\n", summary.CostEfficiencyScore)
+	if summary.CostEfficiencyScore > 0 {
+		fmt.Printf("\n💰 Cost Efficiency Score: %.2f (quality per joule per token)\n", summary.CostEfficiencyScore)
+	} else {
+		fmt.Println("\n💰 Cost Efficiency Score: n/a (no energy readings)")
+	}
+	printEnergyCosts(summary.Energy, summary.KWhPrice)
 
 	fmt.Println("\n⚙️ Recommended Configuration:")
 	config := summary.RecommendedConfig
@@ -837,7 +1368,11 @@ func printLowSpecSummary(summary BenchmarkSummary, profile HardwareProfile) {
 		fmt.Printf("  Fallback Model: %s\n", config.FallbackModel)
 	}
 	fmt.Printf("  Context Length: %d tokens\n", config.ContextLength)
-	fmt.Printf("  Concurrent Requests: %d\n", config.ConcurrentRequests)
+	if config.ConcurrencyMeasured {
+		fmt.Printf("  Concurrent Requests: %d\n", config.ConcurrentRequests)
+	} else {
+		fmt.Printf("  Concurrent Requests: %d (unmeasured; --concurrency=N sweeps it)\n", config.ConcurrentRequests)
+	}
 	fmt.Printf("  Memory Buffer: %dMB\n", config.MemoryBuffer)
 	fmt.Printf("  Swap Recommendation: %s\n", config.SwapRecommendation)
 
@@ -863,7 +1398,7 @@ echo "🔧 Configuring QRY AI for low-spec device optimization..."
 
 # Ollama optimizations
 export OLLAMA_MAX_LOADED_MODELS=1
-export OLLAMA_NUM_PARALLEL=1
+export OLLAMA_NUM_PARALLEL=%s
 export OLLAMA_FLASH_ATTENTION=1
 
 # Primary model for uroboro
@@ -882,8 +1417,9 @@ echo "💾 Memory Buffer: %dMB"
 		profile.DeviceName,
 		time.Now().Format("2006-01-02 15:04:05"),
 		profile.ThermalProfile,
-		profile.AvailableRAM,
+		profile.effectiveAvailableRAM(),
 		profile.StorageType,
+		config.EnvironmentVars["OLLAMA_NUM_PARALLEL"],
 		config.PrimaryModel,
 		config.ContextLength,
 		config.ConcurrentRequests,
@@ -933,15 +1469,15 @@ echo "💾 Memory Buffer: %dMB"
 
 ### Quick Setup
 1. Apply configuration:
-   ```bash
+   `+"```"+`bash
    source %s
-   ```
+   `+"```"+`
 
 2. Test with uroboro:
-   ```bash
+   `+"```"+`bash
    cd ../../labs/projects/uroboro
    ./uroboro capture "Testing low-spec optimization"
-   ```
+   `+"```"+`
 
 ### Recommended Models
 - Primary: %s
@@ -956,19 +1492,19 @@ echo "💾 Memory Buffer: %dMB"
 %s
 
 ### Troubleshooting
-- If you get OOM errors, try: `sudo swapon -s` to check swap
-- Monitor memory: `htop` or `free -m`
+- If you get OOM errors, try: `+"`sudo swapon -s`"+` to check swap
+- Monitor memory: `+"`htop`"+` or `+"`free -m`"+`
 - If thermal throttling occurs, reduce concurrent requests to 1
 
 Generated: %s
 `,
 		profile.DeviceName,
 		profile.ThermalProfile,
-		profile.AvailableRAM,
+		profile.effectiveAvailableRAM(),
 		scriptPath,
 		config.PrimaryModel,
 		formatOptimalModels(summary.OptimalModels),
-		profile.AvailableRAM,
+		profile.effectiveAvailableRAM(),
 		config.MemoryBuffer,
 		config.SwapRecommendation,
 		strings.Join(summary.PerformanceInsights, "\n- "),
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestCostEfficiencyScoreUsesEnergyOnly(t *testing.T) {
+	measured := BenchmarkResult{Success: true, QualityScore: 4, Energy: &EnergyUsage{Source: "rapl", JoulesPerToken: 2}}
+	estimated := BenchmarkResult{Success: true, QualityScore: 3, Energy: &EnergyUsage{Source: "tdp-estimate", JoulesPerToken: 0.5}}
+	// Memory-only runs used to be averaged in as quality x speed per MB
+	memoryOnly := BenchmarkResult{Success: true, QualityScore: 5, TokensPerSecond: 20, PeakMemoryMB: 2000}
+	uncounted := BenchmarkResult{Success: true, QualityScore: 5, Energy: &EnergyUsage{Source: "rapl", Joules: 30}}
+	failed := BenchmarkResult{QualityScore: 5, Energy: &EnergyUsage{Source: "rapl", JoulesPerToken: 1}}
+
+	tests := []struct {
+		name    string
+		results []BenchmarkResult
+		score   float64
+	}{
+		// (4/2 + 3/0.5) / 2
+		{"energy readings", []BenchmarkResult{measured, estimated}, 4},
+		{"other runs left out", []BenchmarkResult{measured, estimated, memoryOnly, uncounted, failed}, 4},
+		{"no energy readings", []BenchmarkResult{memoryOnly, uncounted}, 0},
+		{"nothing", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if score := calculateCostEfficiencyScore(tt.results, HardwareProfile{}); score != tt.score {
+				t.Errorf("score = %v, want %v", score, tt.score)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CgroupLimits are the limits of the cgroup the benchmark runs in, e.g.
+// a container's memory.max and cpu.max. Limits set on ancestor cgroups
+// apply too, so each is the tightest along the path.
+type CgroupLimits struct {
+	Version       int     `json:"version"` // 1 or 2
+	Path          string  `json:"path"`
+	MemoryLimitMB int64   `json:"memory_limit_mb"` // 0 when unlimited
+	AvailableMB   int64   `json:"available_mb"`    // limit minus the working set; 0 when unlimited
+	CPUQuota      float64 `json:"cpu_quota"`       // cores' worth of CPU time per period; 0 when unlimited
+	CPUSetCores   int     `json:"cpuset_cores"`    // CPUs the cgroup may run on; 0 when not listed
+}
+
+// unlimitedBytes is the threshold above which a v1 limit means "no limit";
+// the kernel reports PAGE_COUNTER_MAX rounded to pages
+const unlimitedBytes = 1 << 60
+
+// readCgroupLimits reads the limits of this process's cgroup from procRoot
+// and the cgroup filesystem under sysRoot. It returns nil when neither
+// memory nor CPU is limited.
+func readCgroupLimits(procRoot, sysRoot string) *CgroupLimits {
+	unified, v1, ok := readProcCgroup(procRoot)
+	if !ok {
+		return nil
+	}
+
+	root := filepath.Join(sysRoot, "fs", "cgroup")
+	limits := &CgroupLimits{}
+	if path, ok := v1["memory"]; ok {
+		limits.Version, limits.Path = 1, path
+		readV1Limits(limits, root, v1)
+	} else if unified != "" {
+		limits.Version, limits.Path = 2, unified
+		readV2Limits(limits, root, unified)
+	} else {
+		return nil
+	}
+
+	if limits.MemoryLimitMB == 0 && limits.CPUQuota == 0 {
+		return nil
+	}
+	return limits
+}
+
+// readProcCgroup reads /proc/self/cgroup under procRoot into the cgroup v2
+// path, if any, and the v1 path of each controller
+func readProcCgroup(procRoot string) (string, map[string]string, bool) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "self", "cgroup"))
+	if err != nil {
+		return "", nil, false
+	}
+
+	// Lines are "hierarchy:controllers:path"; cgroup v2 is "0::path"
+	var unified string
+	v1 := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			unified = parts[2]
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			v1[controller] = parts[2]
+		}
+	}
+	return unified, v1, true
+}
+
+func readV2Limits(limits *CgroupLimits, root, path string) {
+	dirs := cgroupAncestors(root, path)
+	if len(dirs) == 0 {
+		return
+	}
+
+	memoryLimit := int64(0)
+	for _, dir := range dirs {
+		if value := readSysString(filepath.Join(dir, "memory.max")); value != "" && value != "max" {
+			if limit, err := strconv.ParseInt(value, 10, 64); err == nil && (memoryLimit == 0 || limit < memoryLimit) {
+				memoryLimit = limit
+			}
+		}
+
+		// "max 100000" or "<quota> <period>", both in microseconds
+		if fields := strings.Fields(readSysString(filepath.Join(dir, "cpu.max"))); len(fields) == 2 && fields[0] != "max" {
+			quota, errQ := strconv.ParseFloat(fields[0], 64)
+			period, errP := strconv.ParseFloat(fields[1], 64)
+			if errQ == nil && errP == nil && period > 0 {
+				limits.tightenCPU(quota / period)
+			}
+		}
+	}
+
+	limits.CPUSetCores = countCPUList(readSysString(filepath.Join(dirs[0], "cpuset.cpus.effective")))
+	if memoryLimit > 0 {
+		current, _ := readSysInt(filepath.Join(dirs[0], "memory.current"))
+		inactive := readStatValue(filepath.Join(dirs[0], "memory.stat"), "inactive_file")
+		limits.setMemory(memoryLimit, current-inactive)
+	}
+}
+
+func readV1Limits(limits *CgroupLimits, root string, paths map[string]string) {
+	memoryDirs := cgroupAncestors(filepath.Join(root, "memory"), paths["memory"])
+	memoryLimit := int64(0)
+	for _, dir := range memoryDirs {
+		if limit, ok := readSysInt(filepath.Join(dir, "memory.limit_in_bytes")); ok && limit < unlimitedBytes && (memoryLimit == 0 || limit < memoryLimit) {
+			memoryLimit = limit
+		}
+	}
+	if memoryLimit > 0 && len(memoryDirs) > 0 {
+		usage, _ := readSysInt(filepath.Join(memoryDirs[0], "memory.usage_in_bytes"))
+		inactive := readStatValue(filepath.Join(memoryDirs[0], "memory.stat"), "total_inactive_file")
+		limits.setMemory(memoryLimit, usage-inactive)
+	}
+
+	// cpu is usually co-mounted with cpuacct
+	for _, mount := range []string{"cpu", "cpu,cpuacct", "cpuacct,cpu"} {
+		dirs := cgroupAncestors(filepath.Join(root, mount), paths["cpu"])
+		for _, dir := range dirs {
+			quota, okQ := readSysInt(filepath.Join(dir, "cpu.cfs_quota_us"))
+			period, okP := readSysInt(filepath.Join(dir, "cpu.cfs_period_us"))
+			if okQ && okP && quota > 0 && period > 0 {
+				limits.tightenCPU(float64(quota) / float64(period))
+			}
+		}
+		if len(dirs) > 0 {
+			break
+		}
+	}
+
+	if dirs := cgroupAncestors(filepath.Join(root, "cpuset"), paths["cpuset"]); len(dirs) > 0 {
+		cpus := readSysString(filepath.Join(dirs[0], "cpuset.effective_cpus"))
+		if cpus == "" {
+			cpus = readSysString(filepath.Join(dirs[0], "cpuset.cpus"))
+		}
+		limits.CPUSetCores = countCPUList(cpus)
+	}
+}
+
+// cgroupAncestors lists the cgroup's directory and its ancestors up to the
+// mount root. Inside a container the process's path often isn't visible
+// because the container's own cgroup is mounted as the root; the walk then
+// starts at the root.
+func cgroupAncestors(mount, path string) []string {
+	if _, err := os.Stat(mount); err != nil {
+		return nil
+	}
+
+	dir := filepath.Join(mount, path)
+	if _, err := os.Stat(dir); err != nil {
+		dir = mount
+	}
+
+	var dirs []string
+	for {
+		dirs = append(dirs, dir)
+		if dir == mount || !strings.HasPrefix(dir, mount) {
+			return dirs
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func (l *CgroupLimits) tightenCPU(cores float64) {
+	if l.CPUQuota == 0 || cores < l.CPUQuota {
+		l.CPUQuota = cores
+	}
+}
+
+// setMemory records the limit and what's left of it given the working set
+// (usage without inactive page cache, which the kernel reclaims first)
+func (l *CgroupLimits) setMemory(limitBytes, workingSetBytes int64) {
+	l.MemoryLimitMB = limitBytes / (1024 * 1024)
+	if workingSetBytes < 0 {
+		workingSetBytes = 0
+	}
+	l.AvailableMB = (limitBytes - workingSetBytes) / (1024 * 1024)
+	if l.AvailableMB < 0 {
+		l.AvailableMB = 0
+	}
+}
+
+// countCPUList counts the CPUs in a list like "0-3,6"
+func countCPUList(list string) int {
+	count := 0
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		low, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		high := low
+		if len(bounds) == 2 {
+			if high, err = strconv.Atoi(bounds[1]); err != nil {
+				continue
+			}
+		}
+		count += high - low + 1
+	}
+	return count
+}
+
+// readStatValue returns a "key value" line's value from a memory.stat file
+func readStatValue(path, key string) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == key {
+			value, _ := strconv.ParseInt(fields[1], 10, 64)
+			return value
+		}
+	}
+	return 0
+}
+
+// effectiveAvailableRAM is the RAM in MB the benchmark can actually use:
+// the host's available RAM, capped by what's left of the cgroup limit
+func (p HardwareProfile) effectiveAvailableRAM() int64 {
+	if p.Cgroup != nil && p.Cgroup.MemoryLimitMB > 0 && p.Cgroup.AvailableMB < p.AvailableRAM {
+		return p.Cgroup.AvailableMB
+	}
+	return p.AvailableRAM
+}
+
+// formatCgroupLimits summarizes the limits for the hardware profile
+func formatCgroupLimits(limits *CgroupLimits) string {
+	var parts []string
+	if limits.MemoryLimitMB > 0 {
+		parts = append(parts, fmt.Sprintf("memory %dMB (%dMB free)", limits.MemoryLimitMB, limits.AvailableMB))
+	}
+	if limits.CPUQuota > 0 {
+		parts = append(parts, fmt.Sprintf("CPU %.2g cores", limits.CPUQuota))
+	}
+	return fmt.Sprintf("cgroup v%d %s: %s", limits.Version, limits.Path, strings.Join(parts, ", "))
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConcurrencyLevel is the outcome of firing Concurrency requests at once
+type ConcurrencyLevel struct {
+	Concurrency              int                `json:"concurrency"`
+	Requests                 int                `json:"requests"`
+	Failures                 int                `json:"failures"`
+	WallTime                 time.Duration      `json:"wall_time"`
+	AggregateTokensPerSecond float64            `json:"aggregate_tokens_per_second"` // output tokens of all requests / wall time
+	Latency                  LatencyPercentiles `json:"latency"`                     // per-request response time
+	MemoryGrowthMB           int64              `json:"memory_growth_mb"`            // drop in available RAM at the level's peak
+}
+
+// ConcurrencySweep measures how a model's throughput scales with parallel requests
+type ConcurrencySweep struct {
+	Model          string             `json:"model"`
+	Scenario       string             `json:"scenario"`
+	LatencyBudget  time.Duration      `json:"latency_budget"`
+	Levels         []ConcurrencyLevel `json:"levels"`
+	Recommended    int                `json:"recommended"` // highest useful concurrency
+	Reason         string             `json:"reason"`
+	ServerParallel int                `json:"server_parallel,omitempty"` // OLLAMA_NUM_PARALLEL in the sweep's environment; 0 if unset or not Ollama
+	Capped         bool               `json:"capped,omitempty"`          // the recommendation reached ServerParallel, so higher levels may only have queued
+}
+
+// plateauGain is the throughput gain below which adding a request is not worth it
+const plateauGain = 0.10
+
+// runConcurrencySweep fires 1..MaxConcurrency simultaneous copies of scenario
+// at model. The runtime's own parallelism setting (OLLAMA_NUM_PARALLEL) still
+// applies, so requests beyond it queue and show up as latency: the sweep
+// can't measure past the server's setting. The server's environment can't be
+// read, so the setting is taken from this process's, as when both run from
+// the same shell.
+func runConcurrencySweep(backend Backend, model string, scenario TestScenario, opts LowSpecOptions) *ConcurrencySweep {
+	sweep := &ConcurrencySweep{
+		Model:         model,
+		Scenario:      scenario.Name,
+		LatencyBudget: opts.LatencyBudget,
+	}
+	if backend.Name() == "ollama" {
+		sweep.ServerParallel, _ = strconv.Atoi(os.Getenv("OLLAMA_NUM_PARALLEL"))
+	}
+	options := scenario.Options.withDefaults(GenerationOptions{MaxTokens: scenario.MaxTokens})
+
+	fmt.Printf("\n🔀 Concurrency sweep: %s, 1..%d parallel requests (%s)\n", model, opts.MaxConcurrency, scenario.Name)
+
+	for concurrency := 1; concurrency <= opts.MaxConcurrency; concurrency++ {
+		level := runConcurrencyLevel(backend, GenerateRequest{Model: model, Prompt: scenario.Prompt, Options: options}, concurrency)
+		sweep.Levels = append(sweep.Levels, level)
+
+		fmt.Printf("  x%d: %.1f t/s aggregate, p50 %v, p90 %v, +%dMB",
+			concurrency, level.AggregateTokensPerSecond,
+			level.Latency.P50.Round(time.Millisecond),
+			level.Latency.P90.Round(time.Millisecond),
+			level.MemoryGrowthMB)
+		if level.Failures > 0 {
+			fmt.Printf(", %d/%d failed", level.Failures, level.Requests)
+		}
+		fmt.Println()
+
+		// Past the budget every higher level only gets slower
+		if level.Failures == level.Requests || level.Latency.P90 > opts.LatencyBudget {
+			break
+		}
+
+		// Brief pause to let system recover
+		time.Sleep(recoveryPause)
+	}
+
+	sweep.Recommended, sweep.Reason = recommendConcurrency(sweep.Levels, opts.LatencyBudget)
+	if markServerParallelCap(sweep) {
+		fmt.Printf("  ⚠️  Requests beyond OLLAMA_NUM_PARALLEL=%d queue on the server; the sweep can't measure past it\n", sweep.ServerParallel)
+	}
+	return sweep
+}
+
+// markServerParallelCap flags a recommendation that reached the server's
+// parallelism setting: beyond it requests only queued, so a higher setting
+// might sustain more
+func markServerParallelCap(sweep *ConcurrencySweep) bool {
+	if sweep.ServerParallel <= 0 || sweep.Recommended < sweep.ServerParallel {
+		return false
+	}
+	sweep.Capped = true
+	sweep.Reason += fmt.Sprintf("; capped by OLLAMA_NUM_PARALLEL=%d, raise it on the server and re-run to measure further", sweep.ServerParallel)
+	return true
+}
+
+func runConcurrencyLevel(backend Backend, req GenerateRequest, concurrency int) ConcurrencyLevel {
+	level := ConcurrencyLevel{Concurrency: concurrency, Requests: concurrency}
+
+	memoryGrowth := sampleMemoryGrowth()
+
+	var wg sync.WaitGroup
+	latencies := make([]time.Duration, concurrency)
+	tokens := make([]int, concurrency)
+	errs := make([]error, concurrency)
+
+	start := time.Now()
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+			defer cancel()
+
+			requestStart := time.Now()
+			resp, err := backend.Generate(ctx, req)
+			latencies[i] = time.Since(requestStart)
+			tokens[i] = resp.Metrics.EvalCount
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	level.WallTime = time.Since(start)
+
+	level.MemoryGrowthMB = memoryGrowth()
+
+	var succeeded []time.Duration
+	totalTokens := 0
+	for i, err := range errs {
+		if err != nil {
+			level.Failures++
+			continue
+		}
+		succeeded = append(succeeded, latencies[i])
+		totalTokens += tokens[i]
+	}
+	level.Latency = computeLatencyPercentiles(succeeded)
+	if level.WallTime > 0 {
+		level.AggregateTokensPerSecond = float64(totalTokens) / level.WallTime.Seconds()
+	}
+
+	return level
+}
+
+// recommendConcurrency picks the level where throughput stops improving by
+// more than plateauGain, or the last level that stayed within the latency
+// budget, whichever comes first
+func recommendConcurrency(levels []ConcurrencyLevel, budget time.Duration) (int, string) {
+	recommended := 0
+	best := 0.0
+	atLeastOne := func() int {
+		if recommended < 1 {
+			return 1
+		}
+		return recommended
+	}
+
+	for _, level := range levels {
+		if level.Failures > 0 {
+			return atLeastOne(), fmt.Sprintf("requests failed at %d parallel", level.Concurrency)
+		}
+		if level.Latency.P90 > budget {
+			return atLeastOne(), fmt.Sprintf("p90 latency %v exceeds the %v budget at %d parallel",
+				level.Latency.P90.Round(time.Millisecond), budget, level.Concurrency)
+		}
+		if recommended > 0 && level.AggregateTokensPerSecond < best*(1+plateauGain) {
+			return recommended, fmt.Sprintf("throughput plateaus at %.1f t/s beyond %d parallel", best, recommended)
+		}
+		recommended = level.Concurrency
+		best = level.AggregateTokensPerSecond
+	}
+
+	if recommended == 0 {
+		return 1, "no concurrency levels measured"
+	}
+	return recommended, fmt.Sprintf("throughput still rising at %d parallel (%.1f t/s); try a higher --concurrency", recommended, best)
+}
+
+// applyConcurrencyRecommendation replaces the default of one request at a
+// time with the measured concurrency
+func applyConcurrencyRecommendation(config *RecommendedConfig, sweep *ConcurrencySweep) {
+	if sweep == nil || sweep.Recommended == 0 {
+		return
+	}
+	config.ConcurrentRequests = sweep.Recommended
+	config.ConcurrencyMeasured = true
+	config.EnvironmentVars["OLLAMA_NUM_PARALLEL"] = strconv.Itoa(sweep.Recommended)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// level is a concurrency level with its throughput and p90 latency
+func level(concurrency int, tokensPerSecond float64, p90 time.Duration, failures int) ConcurrencyLevel {
+	return ConcurrencyLevel{
+		Concurrency:              concurrency,
+		Requests:                 concurrency,
+		Failures:                 failures,
+		AggregateTokensPerSecond: tokensPerSecond,
+		Latency:                  LatencyPercentiles{P90: p90},
+	}
+}
+
+func TestRecommendConcurrency(t *testing.T) {
+	budget := 10 * time.Second
+	tests := []struct {
+		name        string
+		levels      []ConcurrencyLevel
+		recommended int
+		reason      string
+	}{
+		{"nothing measured", nil, 1, "no concurrency levels measured"},
+		{"still rising", []ConcurrencyLevel{level(1, 10, 2*time.Second, 0), level(2, 18, 3*time.Second, 0), level(3, 25, 4*time.Second, 0)},
+			3, "throughput still rising at 3 parallel (25.0 t/s)"},
+		// 2 -> 3 adds 5%, under the 10% worth having
+		{"plateau", []ConcurrencyLevel{level(1, 10, 2*time.Second, 0), level(2, 18, 3*time.Second, 0), level(3, 18.9, 4*time.Second, 0), level(4, 30, 5*time.Second, 0)},
+			2, "throughput plateaus at 18.0 t/s beyond 2 parallel"},
+		{"throughput drops", []ConcurrencyLevel{level(1, 10, time.Second, 0), level(2, 8, time.Second, 0)},
+			1, "plateaus at 10.0 t/s beyond 1 parallel"},
+		{"over budget", []ConcurrencyLevel{level(1, 10, 4*time.Second, 0), level(2, 19, 9*time.Second, 0), level(3, 27, 11*time.Second, 0)},
+			2, "p90 latency 11s exceeds the 10s budget at 3 parallel"},
+		// Even one request at a time is over budget, but that's the floor
+		{"over budget alone", []ConcurrencyLevel{level(1, 10, 12*time.Second, 0)},
+			1, "exceeds the 10s budget at 1 parallel"},
+		{"failures", []ConcurrencyLevel{level(1, 10, time.Second, 0), level(2, 19, time.Second, 0), level(3, 20, time.Second, 1)},
+			2, "requests failed at 3 parallel"},
+		{"failures alone", []ConcurrencyLevel{level(1, 0, 0, 1)},
+			1, "requests failed at 1 parallel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommended, reason := recommendConcurrency(tt.levels, budget)
+			if recommended != tt.recommended || !strings.Contains(reason, tt.reason) {
+				t.Errorf("recommended %d (%s), want %d (%s)", recommended, reason, tt.recommended, tt.reason)
+			}
+		})
+	}
+}
+
+func TestMarkServerParallelCap(t *testing.T) {
+	tests := []struct {
+		serverParallel, recommended int
+		capped                      bool
+	}{
+		{0, 4, false},
+		{4, 2, false},
+		{4, 4, true},
+		{2, 3, true},
+	}
+
+	for _, tt := range tests {
+		sweep := &ConcurrencySweep{ServerParallel: tt.serverParallel, Recommended: tt.recommended, Reason: "throughput still rising"}
+		if capped := markServerParallelCap(sweep); capped != tt.capped || sweep.Capped != tt.capped {
+			t.Errorf("server %d, recommended %d: capped %v", tt.serverParallel, tt.recommended, capped)
+		}
+		if tt.capped != strings.Contains(sweep.Reason, "capped by OLLAMA_NUM_PARALLEL") {
+			t.Errorf("reason = %q", sweep.Reason)
+		}
+	}
+}
+
+func TestConcurrencyRecommendation(t *testing.T) {
+	// Plenty of RAM alone doesn't make parallel requests worthwhile
+	config := generateRecommendedConfig(nil, HardwareProfile{TotalRAM: 32768, AvailableRAM: 24576, CPUCores: 16})
+	if config.ConcurrentRequests != 1 || config.ConcurrencyMeasured || config.EnvironmentVars["OLLAMA_NUM_PARALLEL"] != "1" {
+		t.Errorf("default = %d, measured %v, env %v", config.ConcurrentRequests, config.ConcurrencyMeasured, config.EnvironmentVars)
+	}
+
+	applyConcurrencyRecommendation(&config, nil)
+	applyConcurrencyRecommendation(&config, &ConcurrencySweep{})
+	if config.ConcurrencyMeasured {
+		t.Error("a sweep without a recommendation counted as a measurement")
+	}
+
+	applyConcurrencyRecommendation(&config, &ConcurrencySweep{Recommended: 3})
+	if config.ConcurrentRequests != 3 || !config.ConcurrencyMeasured || config.EnvironmentVars["OLLAMA_NUM_PARALLEL"] != "3" {
+		t.Errorf("measured = %d, measured %v, env %v", config.ConcurrentRequests, config.ConcurrencyMeasured, config.EnvironmentVars)
+	}
+}
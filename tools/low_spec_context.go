@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minSweepContext is the first num_ctx a context sweep tries
+const minSweepContext = 1024
+
+// ContextStep is one num_ctx setting of a context-length sweep
+type ContextStep struct {
+	NumCtx           int           `json:"num_ctx"`
+	PromptTokens     int           `json:"prompt_tokens"` // as counted by the backend
+	Success          bool          `json:"success"`
+	Error            string        `json:"error,omitempty"`
+	ResponseTime     time.Duration `json:"response_time"`
+	LoadTime         time.Duration `json:"load_time"`    // reloading the model at this num_ctx, as reported by the backend
+	ServingTime      time.Duration `json:"serving_time"` // response time without the load, judged against the latency budget
+	TimeToFirstToken time.Duration `json:"time_to_first_token"`
+	PrefillRate      float64       `json:"prefill_tokens_per_second"`
+	DecodeRate       float64       `json:"decode_tokens_per_second"`
+	MemoryMB         int64         `json:"memory_mb"` // drop in available RAM while loaded at this size
+	WithinTargets    bool          `json:"within_targets"`
+}
+
+// ContextSweep measures what larger context windows cost on this device
+type ContextSweep struct {
+	Model         string        `json:"model"`
+	MemoryLimitMB int64         `json:"memory_limit_mb"` // available RAM minus the recommended buffer
+	LatencyBudget time.Duration `json:"latency_budget"`
+	Steps         []ContextStep `json:"steps"`
+	Recommended   int           `json:"recommended"` // 0 when no step met the targets
+	Reason        string        `json:"reason"`
+}
+
+// contextSweepFiller is repeated to build prompts of a given size
+const contextSweepFiller = "Refactored the request handler to stream responses, added retries with backoff to the database client, and documented the new configuration flags for the deployment pipeline. "
+
+// runContextSweep runs model at doubling num_ctx settings, each with a prompt
+// filling about half the window, until a step exceeds memoryLimitMB or the
+// latency budget. The model is unloaded before each step (where the backend
+// allows) so the memory figure covers the model and its KV cache.
+// OpenAI-compatible servers fix their context at startup, so for them only
+// the prompt size grows.
+func runContextSweep(backend Backend, model string, info ModelInfo, opts LowSpecOptions, memoryLimitMB int64) *ContextSweep {
+	sweep := &ContextSweep{
+		Model:         model,
+		MemoryLimitMB: memoryLimitMB,
+		LatencyBudget: opts.LatencyBudget,
+	}
+
+	maxContext := opts.MaxContext
+	if maxContext == 0 {
+		maxContext = 8192
+		if info.ContextLength > 0 {
+			maxContext = info.ContextLength
+		}
+	}
+	if info.ContextLength > 0 && maxContext > info.ContextLength {
+		maxContext = info.ContextLength
+	}
+
+	fmt.Printf("\n📏 Context sweep: %s, num_ctx %d..%d (memory limit %dMB)\n", model, minSweepContext, maxContext, memoryLimitMB)
+
+	for numCtx := minSweepContext; numCtx <= maxContext; numCtx *= 2 {
+		step := runContextStep(backend, model, numCtx, memoryLimitMB, opts.LatencyBudget)
+		sweep.Steps = append(sweep.Steps, step)
+
+		if !step.Success {
+			fmt.Printf("  num_ctx %d: ❌ %s\n", numCtx, step.Error)
+			break
+		}
+		fmt.Printf("  num_ctx %d: %d prompt tokens, prefill %.1f t/s, decode %.1f t/s, TTFT %v, %v (+%v load), +%dMB\n",
+			numCtx, step.PromptTokens, step.PrefillRate, step.DecodeRate,
+			step.TimeToFirstToken.Round(time.Millisecond), step.ServingTime.Round(time.Millisecond),
+			step.LoadTime.Round(time.Millisecond), step.MemoryMB)
+		if !step.WithinTargets {
+			break
+		}
+
+		// Brief pause to let system recover
+		time.Sleep(recoveryPause)
+	}
+
+	sweep.Recommended, sweep.Reason = recommendContextLength(sweep)
+	return sweep
+}
+
+func runContextStep(backend Backend, model string, numCtx int, memoryLimitMB int64, budget time.Duration) ContextStep {
+	step := ContextStep{NumCtx: numCtx}
+
+	// Changing num_ctx makes Ollama reload the model anyway; unloading first
+	// lets the memory sample start from a clean baseline
+	if _, err := unloadModel(backend, model); err != nil {
+		fmt.Printf("  ⚠️  Could not unload %s: %v\n", model, err)
+	}
+
+	req := GenerateRequest{
+		Model:   model,
+		Prompt:  contextSweepPrompt(numCtx / 2),
+		Options: GenerationOptions{MaxTokens: 128, NumCtx: numCtx},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	memoryGrowth := sampleMemoryGrowth()
+	start := time.Now()
+	resp, err := backend.Generate(ctx, req)
+	step.ResponseTime = time.Since(start)
+	step.MemoryMB = memoryGrowth()
+
+	if err != nil {
+		step.Error = err.Error()
+		return step
+	}
+
+	step.Success = true
+	step.PromptTokens = resp.Metrics.PromptEvalCount
+	step.TimeToFirstToken = resp.Timing.TimeToFirstToken
+	step.PrefillRate = resp.Metrics.PrefillRate()
+	step.DecodeRate = resp.Metrics.DecodeRate()
+	// The reload is forced by the sweep, not paid per request in use
+	step.LoadTime = resp.Metrics.LoadDuration
+	step.ServingTime = step.ResponseTime - step.LoadTime
+	if step.ServingTime < 0 {
+		step.ServingTime = step.ResponseTime
+	}
+	step.WithinTargets = step.ServingTime <= budget && (memoryLimitMB <= 0 || step.MemoryMB <= memoryLimitMB)
+
+	return step
+}
+
+// contextSweepPrompt builds a summarization prompt of roughly tokens tokens,
+// assuming about three words to every four tokens
+func contextSweepPrompt(tokens int) string {
+	words := tokens * 3 / 4
+	fillerWords := len(strings.Fields(contextSweepFiller))
+
+	var prompt strings.Builder
+	prompt.WriteString("Development log:\n")
+	for written := 0; written < words; written += fillerWords {
+		prompt.WriteString(contextSweepFiller)
+	}
+	prompt.WriteString("\n\nSummarize the changes in the log above in three bullet points.")
+	return prompt.String()
+}
+
+// recommendContextLength picks the largest step that met both targets
+func recommendContextLength(sweep *ContextSweep) (int, string) {
+	recommended := 0
+	for _, step := range sweep.Steps {
+		if step.Success && step.WithinTargets {
+			recommended = step.NumCtx
+		}
+	}
+
+	if len(sweep.Steps) == 0 {
+		return 0, "no context lengths measured"
+	}
+	last := sweep.Steps[len(sweep.Steps)-1]
+
+	var limit string
+	switch {
+	case !last.Success:
+		limit = fmt.Sprintf("num_ctx %d failed", last.NumCtx)
+	case last.ServingTime > sweep.LatencyBudget:
+		limit = fmt.Sprintf("num_ctx %d took %v without loading, over the %v budget", last.NumCtx, last.ServingTime.Round(time.Millisecond), sweep.LatencyBudget)
+	case sweep.MemoryLimitMB > 0 && last.MemoryMB > sweep.MemoryLimitMB:
+		limit = fmt.Sprintf("num_ctx %d used %dMB, over the %dMB limit", last.NumCtx, last.MemoryMB, sweep.MemoryLimitMB)
+	default:
+		limit = fmt.Sprintf("largest setting tried was %d", last.NumCtx)
+	}
+
+	if recommended == 0 {
+		return 0, limit + "; keeping the RAM-based default"
+	}
+	return recommended, limit
+}
+
+// applyContextRecommendation replaces the RAM-based context length with the
+// measured one
+func applyContextRecommendation(config *RecommendedConfig, sweep *ContextSweep) {
+	if sweep == nil || sweep.Recommended == 0 {
+		return
+	}
+	config.ContextLength = sweep.Recommended
+	config.EnvironmentVars["OLLAMA_CONTEXT_LENGTH"] = strconv.Itoa(sweep.Recommended)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextStepBudgetExcludesLoad(t *testing.T) {
+	defer disableLocalProbes()()
+
+	// Every step reloads the model, which takes far longer than answering
+	backend := &MockBackend{Script: MockScript{Default: MockResponse{
+		Output:           "three short bullets",
+		PromptTokens:     512,
+		LoadMS:           150,
+		TimeToFirstToken: 10,
+		TokensPerSecond:  300,
+	}}}
+
+	step := runContextStep(backend, "tiny", 1024, 0, 50*time.Millisecond)
+	if !step.Success {
+		t.Fatalf("step = %+v", step)
+	}
+	if step.LoadTime != 150*time.Millisecond {
+		t.Errorf("load time = %v", step.LoadTime)
+	}
+	if step.ResponseTime < step.LoadTime || step.ServingTime != step.ResponseTime-step.LoadTime {
+		t.Errorf("response %v, serving %v", step.ResponseTime, step.ServingTime)
+	}
+	if !step.WithinTargets {
+		t.Errorf("serving took %v, within the budget once the %v load is left out", step.ServingTime, step.LoadTime)
+	}
+
+	// A budget the serving time itself misses still stops the sweep
+	sweep := &ContextSweep{LatencyBudget: time.Millisecond}
+	sweep.Steps = append(sweep.Steps, runContextStep(backend, "tiny", 1024, 0, sweep.LatencyBudget))
+	if sweep.Steps[0].WithinTargets {
+		t.Errorf("step = %+v", sweep.Steps[0])
+	}
+	recommended, reason := recommendContextLength(sweep)
+	if recommended != 0 || !strings.Contains(reason, "without loading, over the 1ms budget") {
+		t.Errorf("recommendation = %d, %q", recommended, reason)
+	}
+}
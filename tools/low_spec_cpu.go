@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CPUUsage is how busy the CPU was while a request ran
+type CPUUsage struct {
+	AvgPercent         float64   `json:"avg_percent"`          // all cores over the whole run
+	PeakPercent        float64   `json:"peak_percent"`         // busiest sampling interval
+	IOWaitPercent      float64   `json:"iowait_percent"`       // idle while waiting on disk: swap, or a model paged in from storage
+	CorePercent        []float64 `json:"core_percent"`         // per core over the whole run
+	SaturatedCores     int       `json:"saturated_cores"`      // cores at least saturatedCorePercent busy
+	RuntimeAvgPercent  float64   `json:"runtime_avg_percent"`  // runtime processes, in percent of one core (200 = two cores)
+	RuntimePeakPercent float64   `json:"runtime_peak_percent"` // busiest sampling interval
+	Bottleneck         string    `json:"bottleneck"`           // "compute", "memory-bandwidth", "swap", "idle" or "mixed"
+}
+
+// cpuSampleInterval is how often /proc/stat is read; shorter intervals make
+// the peak noisy at 100 ticks a second
+const cpuSampleInterval = 500 * time.Millisecond
+
+// saturatedCorePercent is the busy share at which a core counts as saturated
+const saturatedCorePercent = 90
+
+// cpuProbe reads the CPU counters. Multi-host runs replace it while
+// benchmarking a remote host, whose CPU can't be seen from here.
+var cpuProbe = func() (cpuSnapshot, bool) { return readCPUSnapshot("/proc") }
+
+// cpuTimes are cumulative ticks from one /proc/stat line
+type cpuTimes struct {
+	total, idle, iowait uint64
+}
+
+func (t cpuTimes) busy() uint64 {
+	return t.total - t.idle - t.iowait
+}
+
+// cpuSnapshot holds every counter needed to compute usage between two reads
+type cpuSnapshot struct {
+	all     cpuTimes
+	cores   []cpuTimes
+	runtime map[string]uint64 // pid -> utime+stime of the runtime processes
+}
+
+// sampleCPUUsage polls the CPU counters in the background. The returned
+// func stops polling and summarizes the run, or returns nil if the counters
+// can't be read.
+func sampleCPUUsage() func() *CPUUsage {
+	first, ok := cpuProbe()
+	if !ok {
+		return func() *CPUUsage { return nil }
+	}
+
+	usage := &CPUUsage{}
+	previous := first
+	record := func() cpuSnapshot {
+		current, ok := cpuProbe()
+		if !ok {
+			return previous
+		}
+		if percent := busyPercent(previous.all, current.all); percent > usage.PeakPercent {
+			usage.PeakPercent = percent
+		}
+		if percent := runtimeCorePercent(previous, current); percent > usage.RuntimePeakPercent {
+			usage.RuntimePeakPercent = percent
+		}
+		previous = current
+		return current
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(cpuSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				record()
+			}
+		}
+	}()
+
+	return func() *CPUUsage {
+		close(stop)
+		<-done
+		last := record()
+
+		elapsed := tickDelta(first.all.total, last.all.total)
+		if elapsed == 0 {
+			return nil // too short to register a tick
+		}
+		usage.AvgPercent = busyPercent(first.all, last.all)
+		usage.IOWaitPercent = 100 * float64(tickDelta(first.all.iowait, last.all.iowait)) / float64(elapsed)
+		usage.RuntimeAvgPercent = runtimeCorePercent(first, last)
+		if len(first.cores) == len(last.cores) {
+			for i := range last.cores {
+				percent := busyPercent(first.cores[i], last.cores[i])
+				usage.CorePercent = append(usage.CorePercent, percent)
+				if percent >= saturatedCorePercent {
+					usage.SaturatedCores++
+				}
+			}
+		}
+		usage.Bottleneck = classifyBottleneck(*usage)
+		return usage
+	}
+}
+
+func busyPercent(before, after cpuTimes) float64 {
+	elapsed := tickDelta(before.total, after.total)
+	if elapsed == 0 {
+		return 0
+	}
+	return 100 * float64(tickDelta(before.busy(), after.busy())) / float64(elapsed)
+}
+
+// tickDelta guards against counters going backwards, which per-core iowait
+// is documented to do
+func tickDelta(before, after uint64) uint64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
+
+// runtimeCorePercent converts the runtime's ticks between two snapshots into
+// percent of one core. Both files count in the same clock ticks, so the
+// aggregate line divided by the core count gives one core's worth of time.
+// A process first seen in after started in between and counts in full.
+func runtimeCorePercent(before, after cpuSnapshot) float64 {
+	if len(after.cores) == 0 {
+		return 0
+	}
+	perCore := float64(tickDelta(before.all.total, after.all.total)) / float64(len(after.cores))
+	if perCore == 0 {
+		return 0
+	}
+
+	var ticks uint64
+	for pid, used := range after.runtime {
+		ticks += tickDelta(before.runtime[pid], used)
+	}
+	return 100 * float64(ticks) / perCore
+}
+
+// classifyBottleneck guesses what limited a CPU run. Waiting on disk means
+// the model is being paged in or out. Saturated cores mean more compute
+// would help. A busy runtime leaving cores idle while tokens trickle means
+// its threads are stalled on RAM, which is the usual limit for decoding on
+// low-spec devices. An idle CPU means the work ran on a GPU or elsewhere.
+func classifyBottleneck(usage CPUUsage) string {
+	switch {
+	case usage.IOWaitPercent >= 20:
+		return "swap"
+	case usage.AvgPercent >= 85 || (len(usage.CorePercent) > 0 && usage.SaturatedCores*2 >= len(usage.CorePercent)):
+		return "compute"
+	case usage.RuntimeAvgPercent >= 50 && usage.AvgPercent < 60 && usage.IOWaitPercent < 5:
+		return "memory-bandwidth"
+	case usage.AvgPercent < 10:
+		return "idle"
+	}
+	return "mixed"
+}
+
+// readCPUSnapshot reads stat and the runtime processes' counters under procRoot
+func readCPUSnapshot(procRoot string) (cpuSnapshot, bool) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return cpuSnapshot{}, false
+	}
+
+	var snapshot cpuSnapshot
+	found := false
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		times := parseCPUTimes(fields[1:])
+		if fields[0] == "cpu" {
+			snapshot.all = times
+			found = true
+		} else {
+			snapshot.cores = append(snapshot.cores, times)
+		}
+	}
+	if !found {
+		return cpuSnapshot{}, false
+	}
+
+	snapshot.runtime = make(map[string]uint64)
+	pids, _ := runtimeProcesses(procRoot)
+	for _, pid := range pids {
+		if ticks, ok := readProcessTicks(filepath.Join(procRoot, pid, "stat")); ok {
+			snapshot.runtime[pid] = ticks
+		}
+	}
+	return snapshot, true
+}
+
+// parseCPUTimes reads "user nice system idle iowait irq softirq steal ...".
+// Guest time is already included in user and nice, so it isn't added again.
+func parseCPUTimes(fields []string) cpuTimes {
+	var times cpuTimes
+	for i, field := range fields {
+		if i >= 8 {
+			break
+		}
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			continue
+		}
+		times.total += value
+		switch i {
+		case 3:
+			times.idle = value
+		case 4:
+			times.iowait = value
+		}
+	}
+	return times
+}
+
+// readProcessTicks returns utime+stime from /proc/<pid>/stat. The command
+// name may contain spaces, so fields are counted from its closing paren.
+func readProcessTicks(path string) (uint64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	stat := string(data)
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return 0, false
+	}
+
+	// Field 3 (state) is the first after the paren; utime and stime are 14 and 15
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 13 {
+		return 0, false
+	}
+	utime, errU := strconv.ParseUint(fields[11], 10, 64)
+	stime, errS := strconv.ParseUint(fields[12], 10, 64)
+	if errU != nil || errS != nil {
+		return 0, false
+	}
+	return utime + stime, true
+}
+
+// generateCPUInsights reports average CPU load and which bottleneck
+// dominated, so compute-bound devices aren't mistaken for memory- or
+// swap-bound ones
+func generateCPUInsights(results []BenchmarkResult) []string {
+	var insights []string
+	var measured int
+	var avg, peak, iowait float64
+	bottlenecks := make(map[string]int)
+	for _, result := range results {
+		if result.CPU == nil {
+			continue
+		}
+		measured++
+		avg += result.CPU.AvgPercent
+		iowait += result.CPU.IOWaitPercent
+		if result.CPU.PeakPercent > peak {
+			peak = result.CPU.PeakPercent
+		}
+		bottlenecks[result.CPU.Bottleneck]++
+	}
+	if measured == 0 {
+		return nil
+	}
+
+	insights = append(insights, fmt.Sprintf("CPU: average %.0f%%, peak %.0f%%, iowait %.0f%% across %d tests",
+		avg/float64(measured), peak, iowait/float64(measured), measured))
+
+	advice := []struct{ bottleneck, text string }{
+		{"compute", "Compute-bound in %d/%d tests - cores are saturated; a smaller model or lower-bit quantization helps more than extra RAM"},
+		{"memory-bandwidth", "Memory-bandwidth-bound in %d/%d tests - cores have headroom while tokens trickle; a smaller quantization reads less per token, more threads won't help"},
+		{"swap", "Swap-bound in %d/%d tests - the CPU waits on disk; the model doesn't fit in RAM, so pick a smaller one or free memory first"},
+	}
+	for _, item := range advice {
+		if count := bottlenecks[item.bottleneck]; count > 0 {
+			insights = append(insights, fmt.Sprintf(item.text, count, measured))
+		}
+	}
+	return insights
+}
+
+// formatCPUUsage summarizes a run for progress output
+func formatCPUUsage(usage *CPUUsage) string {
+	return fmt.Sprintf("CPU avg %.0f%%, peak %.0f%%, %d/%d cores saturated, iowait %.0f%%, runtime %.0f%% of a core (%s)",
+		usage.AvgPercent, usage.PeakPercent, usage.SaturatedCores, len(usage.CorePercent),
+		usage.IOWaitPercent, usage.RuntimeAvgPercent, usage.Bottleneck)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadCPUSnapshot(t *testing.T) {
+	snapshot, ok := readCPUSnapshot(fixtureProcfs("ollama-runners"))
+	if !ok {
+		t.Fatal("stat not read")
+	}
+
+	// Only the first eight columns count; guest time is already in user
+	if snapshot.all != (cpuTimes{total: 9700, idle: 8000, iowait: 200}) {
+		t.Errorf("all = %+v", snapshot.all)
+	}
+	if want := []cpuTimes{{4900, 3900, 100}, {4800, 4100, 100}}; !reflect.DeepEqual(snapshot.cores, want) {
+		t.Errorf("cores = %+v", snapshot.cores)
+	}
+	// utime+stime of the server and the runner, not of bash
+	if want := map[string]uint64{"100": 300, "200": 800}; !reflect.DeepEqual(snapshot.runtime, want) {
+		t.Errorf("runtime = %v", snapshot.runtime)
+	}
+
+	if _, ok := readCPUSnapshot(fixtureProcfs("no-runtime")); ok {
+		t.Error("read a snapshot without stat")
+	}
+}
+
+func TestClassifyBottleneck(t *testing.T) {
+	fourCores := []float64{50, 50, 50, 50}
+
+	tests := []struct {
+		name  string
+		usage CPUUsage
+		want  string
+	}{
+		{"waiting on disk", CPUUsage{AvgPercent: 90, IOWaitPercent: 20, CorePercent: fourCores, SaturatedCores: 4}, "swap"},
+		{"some iowait", CPUUsage{AvgPercent: 40, IOWaitPercent: 19.9, CorePercent: fourCores}, "mixed"},
+
+		{"busy overall", CPUUsage{AvgPercent: 85, CorePercent: fourCores}, "compute"},
+		{"just under busy", CPUUsage{AvgPercent: 84.9, CorePercent: fourCores}, "mixed"},
+		{"half the cores saturated", CPUUsage{AvgPercent: 50, CorePercent: fourCores, SaturatedCores: 2}, "compute"},
+		{"one core saturated", CPUUsage{AvgPercent: 30, CorePercent: fourCores, SaturatedCores: 1}, "mixed"},
+		// Saturation is judged against the cores that were read
+		{"no per-core counters", CPUUsage{AvgPercent: 30}, "mixed"},
+
+		{"stalled on RAM", CPUUsage{AvgPercent: 59.9, RuntimeAvgPercent: 50, IOWaitPercent: 4.9, CorePercent: fourCores}, "memory-bandwidth"},
+		{"runtime mostly idle", CPUUsage{AvgPercent: 40, RuntimeAvgPercent: 49.9, CorePercent: fourCores}, "mixed"},
+		{"other load on the cores", CPUUsage{AvgPercent: 60, RuntimeAvgPercent: 150, CorePercent: fourCores}, "mixed"},
+		{"paging a little", CPUUsage{AvgPercent: 40, RuntimeAvgPercent: 150, IOWaitPercent: 5, CorePercent: fourCores}, "mixed"},
+
+		{"idle", CPUUsage{AvgPercent: 9.9, CorePercent: fourCores}, "idle"},
+		{"barely busy", CPUUsage{AvgPercent: 10, CorePercent: fourCores}, "mixed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyBottleneck(tt.usage); got != tt.want {
+				t.Errorf("bottleneck = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CPUCapabilities describes what the CPU offers inference: the SIMD
+// extensions llama.cpp's kernels use, the cache sizes and the populated
+// memory channels
+type CPUCapabilities struct {
+	Model          string   `json:"model"`
+	Vendor         string   `json:"vendor,omitempty"`
+	Arch           string   `json:"arch"`            // "x86" or "arm"
+	Features       []string `json:"features"`        // inference-relevant extensions, e.g. "avx2", "fma", "neon", "dotprod"
+	L1dKB          int      `json:"l1d_kb"`          // per core
+	L2KB           int      `json:"l2_kb"`           // per core or cluster
+	L3KB           int      `json:"l3_kb"`           // shared by cpu0's cores; 0 without an L3
+	MemoryChannels int      `json:"memory_channels"` // populated channels from EDAC; 0 when unknown
+}
+
+// simdFeatures maps /proc/cpuinfo flags onto the names used in
+// CPUCapabilities.Features. ARM reports NEON as "asimd" on arm64.
+var simdFeatures = map[string]string{
+	"avx":         "avx",
+	"avx2":        "avx2",
+	"fma":         "fma",
+	"f16c":        "f16c",
+	"avx512f":     "avx512",
+	"avx512_vnni": "avx512_vnni",
+	"avx_vnni":    "avx_vnni",
+	"amx_int8":    "amx",
+	"neon":        "neon",
+	"asimd":       "neon",
+	"asimdhp":     "fp16",
+	"asimddp":     "dotprod",
+	"i8mm":        "i8mm",
+	"sve":         "sve",
+}
+
+// readCPUCapabilities reads /proc/cpuinfo under procRoot and the cache and
+// memory controller topology under sysRoot. It returns false when cpuinfo
+// can't be read.
+func readCPUCapabilities(procRoot, sysRoot string) (CPUCapabilities, bool) {
+	var caps CPUCapabilities
+	data, err := os.ReadFile(filepath.Join(procRoot, "cpuinfo"))
+	if err != nil {
+		return caps, false
+	}
+
+	// Every processor repeats the same block; the first one is enough
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		switch key {
+		case "model name", "Hardware":
+			if caps.Model == "" {
+				caps.Model = value
+			}
+		case "vendor_id":
+			if caps.Vendor == "" {
+				caps.Vendor = value
+			}
+		case "flags", "Features":
+			if len(seen) > 0 {
+				continue
+			}
+			if key == "flags" {
+				caps.Arch = "x86"
+			} else {
+				caps.Arch = "arm"
+			}
+			for _, flag := range strings.Fields(value) {
+				if feature, ok := simdFeatures[flag]; ok && !seen[feature] {
+					seen[feature] = true
+					caps.Features = append(caps.Features, feature)
+				}
+			}
+		}
+	}
+
+	// arm64 kernels often report neither a model name nor Hardware
+	if caps.Model == "" {
+		caps.Model = "unknown"
+	}
+
+	caps.L1dKB, caps.L2KB, caps.L3KB = readCacheSizes(sysRoot)
+	caps.MemoryChannels = countMemoryChannels(sysRoot)
+	return caps, true
+}
+
+// has reports whether the CPU has a feature, by its CPUCapabilities name
+func (c CPUCapabilities) has(feature string) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// readCacheSizes reads cpu0's data and unified caches in KB
+func readCacheSizes(sysRoot string) (int, int, int) {
+	var l1d, l2, l3 int
+	indices, _ := filepath.Glob(filepath.Join(sysRoot, "devices", "system", "cpu", "cpu0", "cache", "index*"))
+	for _, index := range indices {
+		if readSysString(filepath.Join(index, "type")) == "Instruction" {
+			continue
+		}
+		size := parseCacheSize(readSysString(filepath.Join(index, "size")))
+		switch readSysString(filepath.Join(index, "level")) {
+		case "1":
+			l1d = size
+		case "2":
+			l2 = size
+		case "3":
+			l3 = size
+		}
+	}
+	return l1d, l2, l3
+}
+
+// parseCacheSize parses sizes like "48K" or "32M" into KB
+func parseCacheSize(size string) int {
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(size, "K"):
+		size = strings.TrimSuffix(size, "K")
+	case strings.HasSuffix(size, "M"):
+		size = strings.TrimSuffix(size, "M")
+		multiplier = 1024
+	}
+	value, err := strconv.Atoi(size)
+	if err != nil {
+		return 0
+	}
+	return value * multiplier
+}
+
+// countMemoryChannels counts the channels with a populated DIMM across the
+// EDAC memory controllers. Older kernels list ranks instead of DIMMs. Most
+// desktops and laptops don't load an EDAC driver, so 0 is common.
+func countMemoryChannels(sysRoot string) int {
+	channels := make(map[string]bool)
+	controllers, _ := filepath.Glob(filepath.Join(sysRoot, "devices", "system", "edac", "mc", "mc[0-9]*"))
+	for _, controller := range controllers {
+		dimms, _ := filepath.Glob(filepath.Join(controller, "dimm[0-9]*"))
+		ranks, _ := filepath.Glob(filepath.Join(controller, "rank[0-9]*"))
+		for _, dimm := range append(dimms, ranks...) {
+			if size, ok := readSysInt(filepath.Join(dimm, "size")); !ok || size <= 0 {
+				continue
+			}
+			// "channel 0 slot 1", or "csrow 0 channel 1" for ranks
+			fields := strings.Fields(readSysString(filepath.Join(dimm, "dimm_location")))
+			for i := 0; i+1 < len(fields); i++ {
+				if fields[i] == "channel" {
+					channels[filepath.Base(controller)+"/"+fields[i+1]] = true
+				}
+			}
+		}
+	}
+	return len(channels)
+}
+
+// generateCPUCapabilityInsights explains what the CPU's extensions, caches
+// and memory channels mean for decode speed
+func generateCPUCapabilityInsights(caps CPUCapabilities) []string {
+	var insights []string
+
+	switch caps.Arch {
+	case "x86":
+		if !caps.has("avx2") {
+			insights = append(insights, "No AVX2: expect roughly 3x slower decode than on an AVX2 CPU; prefer 3B models")
+		} else if !caps.has("fma") || !caps.has("f16c") {
+			insights = append(insights, "AVX2 without FMA/F16C: builds that require them won't run, and generic ones decode more slowly")
+		}
+		if caps.has("avx512") {
+			insights = append(insights, "AVX-512 available: a llama.cpp build with AVX-512 enabled decodes faster than a generic AVX2 build")
+		}
+	case "arm":
+		if !caps.has("neon") {
+			insights = append(insights, "No NEON: inference runs on scalar code; only 1-3B models are practical")
+		} else if !caps.has("dotprod") {
+			insights = append(insights, "No dotprod: quantized matrix multiplies take about twice as long; prefer 3B models")
+		}
+		if caps.has("i8mm") {
+			insights = append(insights, "i8mm available: Q4_0 models use the faster repacked kernels")
+		}
+	}
+
+	if caps.L3KB > 0 && caps.L3KB < 4096 {
+		insights = append(insights, fmt.Sprintf("Small last-level cache (%dKB): prompt processing spills to RAM; keep contexts short", caps.L3KB))
+	}
+	if caps.MemoryChannels == 1 {
+		insights = append(insights, "Single memory channel: decode is bound by memory bandwidth; a second DIMM can nearly double tokens per second")
+	}
+	return insights
+}
+
+// formatCPUCapabilities summarizes the capabilities for the hardware profile
+func formatCPUCapabilities(caps CPUCapabilities) string {
+	features := "no SIMD extensions"
+	if len(caps.Features) > 0 {
+		features = strings.Join(caps.Features, " ")
+	}
+	summary := fmt.Sprintf("%s - %s; cache L1d %dKB, L2 %dKB", caps.Model, features, caps.L1dKB, caps.L2KB)
+	if caps.L3KB > 0 {
+		summary += fmt.Sprintf(", L3 %dKB", caps.L3KB)
+	}
+	if caps.MemoryChannels > 0 {
+		summary += fmt.Sprintf("; memory channels: %d", caps.MemoryChannels)
+	}
+	return summary
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EmbeddingResult is the outcome of the embedding track for one model
+type EmbeddingResult struct {
+	Model      string `json:"model"`
+	Backend    string `json:"backend"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	Dimensions int    `json:"dimensions"`
+
+	// Performance Metrics
+	LoadDuration        time.Duration      `json:"load_duration"`
+	MemoryMB            int64              `json:"memory_mb"`             // drop in available RAM while loading
+	EmbeddingsPerSecond float64            `json:"embeddings_per_second"` // one batched request
+	LatencyByLength     []EmbeddingLatency `json:"latency_by_length"`
+
+	// Quality Metrics (on the labeled retrieval set)
+	RetrievalTop1 float64 `json:"retrieval_top1"` // share of queries whose best match is the labeled document
+	RetrievalMRR  float64 `json:"retrieval_mrr"`  // mean reciprocal rank of the labeled document
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EmbeddingLatency is the single-input latency at one input length
+type EmbeddingLatency struct {
+	TargetTokens int                `json:"target_tokens"`
+	PromptTokens int                `json:"prompt_tokens"` // as counted by the backend
+	Latency      LatencyPercentiles `json:"latency"`
+}
+
+// retrievalQuery pairs a query with the index of its relevant document
+type retrievalQuery struct {
+	Query    string
+	Relevant int
+}
+
+// embeddingRetrievalDocs and embeddingRetrievalQueries are the labeled
+// similarity set: each query has exactly one relevant document
+var embeddingRetrievalDocs = []string{
+	"Added a circuit breaker to the payment service so failing downstream calls stop cascading",
+	"Migrated the user table to a new schema with a backfill job and zero downtime",
+	"Reduced Docker image size by switching to a multi-stage build on a slim base image",
+	"Fixed a memory leak in the websocket handler caused by unclosed subscriptions",
+	"Wrote onboarding documentation covering local setup, tests and the release process",
+	"Added rate limiting per API key to protect the public endpoints from abuse",
+	"Tuned the Postgres query planner by adding a composite index on orders by customer and date",
+	"Set up nightly backups of the object store with retention and restore drills",
+}
+
+var embeddingRetrievalQueries = []retrievalQuery{
+	{Query: "stop failures in a dependency from taking down payments", Relevant: 0},
+	{Query: "database schema migration without downtime", Relevant: 1},
+	{Query: "make the container image smaller", Relevant: 2},
+	{Query: "websocket process keeps growing in memory", Relevant: 3},
+	{Query: "docs for new developers joining the team", Relevant: 4},
+	{Query: "throttle clients that send too many API requests", Relevant: 5},
+	{Query: "slow orders query needs an index", Relevant: 6},
+	{Query: "disaster recovery for stored files", Relevant: 7},
+}
+
+// embeddingLengths are the approximate input sizes latency is measured at
+var embeddingLengths = []int{16, 128, 512}
+
+// embeddingBatchSize is how many inputs the throughput request carries
+const embeddingBatchSize = 32
+
+// runEmbeddingBenchmarks runs the embedding track for each model
+func runEmbeddingBenchmarks(backend Backend, models []string) []EmbeddingResult {
+	var results []EmbeddingResult
+
+	for _, model := range models {
+		fmt.Printf("\n🧬 Embedding %s ", model)
+		result := runEmbeddingTest(backend, model)
+		results = append(results, result)
+
+		if result.Success {
+			fmt.Printf("✅ %d dims, %.1f emb/s, top-1 %.0f%%, MRR %.2f, %dMB\n",
+				result.Dimensions, result.EmbeddingsPerSecond,
+				result.RetrievalTop1*100, result.RetrievalMRR, result.MemoryMB)
+		} else {
+			fmt.Printf("❌ %s\n", result.Error)
+		}
+
+		// Brief pause to let system recover
+		time.Sleep(recoveryPause)
+	}
+
+	return results
+}
+
+func runEmbeddingTest(backend Backend, model string) EmbeddingResult {
+	result := EmbeddingResult{Model: model, Timestamp: time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	// Load from cold so the memory figure covers the whole model
+	if _, err := unloadModel(backend, model); err != nil {
+		fmt.Printf("⚠️  could not unload: %v ", err)
+	}
+	memoryGrowth := sampleMemoryGrowth()
+	resp, err := embed(ctx, backend, EmbedRequest{Model: model, Input: embeddingRetrievalDocs})
+	result.MemoryMB = memoryGrowth()
+	result.Backend = resp.Backend
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if len(resp.Embeddings) != len(embeddingRetrievalDocs) {
+		result.Error = fmt.Sprintf("got %d embeddings for %d inputs", len(resp.Embeddings), len(embeddingRetrievalDocs))
+		return result
+	}
+	result.LoadDuration = resp.LoadDuration
+	result.Dimensions = len(resp.Embeddings[0])
+	docs := resp.Embeddings
+
+	// Retrieval quality: rank the documents for every labeled query
+	var queries []string
+	for _, query := range embeddingRetrievalQueries {
+		queries = append(queries, query.Query)
+	}
+	resp, err = embed(ctx, backend, EmbedRequest{Model: model, Input: queries})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.RetrievalTop1, result.RetrievalMRR = scoreRetrieval(resp.Embeddings, docs)
+
+	// Throughput: one batched request
+	var batch []string
+	for len(batch) < embeddingBatchSize {
+		batch = append(batch, embeddingRetrievalDocs[len(batch)%len(embeddingRetrievalDocs)])
+	}
+	start := time.Now()
+	if _, err := embed(ctx, backend, EmbedRequest{Model: model, Input: batch}); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.EmbeddingsPerSecond = float64(len(batch)) / time.Since(start).Seconds()
+
+	// Latency by input length, single inputs
+	for _, tokens := range embeddingLengths {
+		latency := EmbeddingLatency{TargetTokens: tokens}
+		input := embeddingInput(tokens)
+
+		var samples []time.Duration
+		for i := 0; i < 3; i++ {
+			start := time.Now()
+			resp, err := embed(ctx, backend, EmbedRequest{Model: model, Input: []string{input}})
+			if err != nil {
+				result.Error = err.Error()
+				return result
+			}
+			samples = append(samples, time.Since(start))
+			latency.PromptTokens = resp.PromptTokens
+		}
+		latency.Latency = computeLatencyPercentiles(samples)
+		result.LatencyByLength = append(result.LatencyByLength, latency)
+	}
+
+	result.Success = true
+	return result
+}
+
+// embeddingInput builds an input of roughly tokens tokens from the corpus
+func embeddingInput(tokens int) string {
+	words := tokens * 3 / 4
+	var input []string
+	for i := 0; len(input) < words; i++ {
+		input = append(input, strings.Fields(embeddingRetrievalDocs[i%len(embeddingRetrievalDocs)])...)
+	}
+	return strings.Join(input[:words], " ")
+}
+
+// scoreRetrieval ranks docs by cosine similarity for each query and returns
+// top-1 accuracy and mean reciprocal rank of the labeled documents
+func scoreRetrieval(queries, docs [][]float64) (float64, float64) {
+	if len(queries) != len(embeddingRetrievalQueries) {
+		return 0, 0
+	}
+
+	var top1, reciprocalRanks float64
+	for i, query := range queries {
+		ranked := make([]int, len(docs))
+		scores := make([]float64, len(docs))
+		for j, doc := range docs {
+			ranked[j] = j
+			scores[j] = cosineSimilarity(query, doc)
+		}
+		sort.SliceStable(ranked, func(a, b int) bool { return scores[ranked[a]] > scores[ranked[b]] })
+
+		for rank, doc := range ranked {
+			if doc == embeddingRetrievalQueries[i].Relevant {
+				if rank == 0 {
+					top1++
+				}
+				reciprocalRanks += 1 / float64(rank+1)
+				break
+			}
+		}
+	}
+
+	n := float64(len(queries))
+	return top1 / n, reciprocalRanks / n
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// bestEmbeddingModel prefers retrieval quality, then throughput
+func bestEmbeddingModel(results []EmbeddingResult) string {
+	best := -1
+	for i, result := range results {
+		if !result.Success {
+			continue
+		}
+		if best < 0 || result.RetrievalMRR > results[best].RetrievalMRR ||
+			(result.RetrievalMRR == results[best].RetrievalMRR && result.EmbeddingsPerSecond > results[best].EmbeddingsPerSecond) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return results[best].Model
+}
+
+func printEmbeddingResults(results []EmbeddingResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println("\n🧬 Embedding Models:")
+	for _, result := range results {
+		if !result.Success {
+			fmt.Printf("  %s: ❌ %s\n", result.Model, result.Error)
+			continue
+		}
+		fmt.Printf("  %s: %d dims, %.1f emb/s, top-1 %.0f%%, MRR %.2f, load %v, %dMB\n",
+			result.Model, result.Dimensions, result.EmbeddingsPerSecond,
+			result.RetrievalTop1*100, result.RetrievalMRR,
+			result.LoadDuration.Round(time.Millisecond), result.MemoryMB)
+		for _, latency := range result.LatencyByLength {
+			fmt.Printf("    ~%d tokens: p50 %v\n", latency.TargetTokens, latency.Latency.P50.Round(time.Millisecond))
+		}
+	}
+}
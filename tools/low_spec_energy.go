@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnergyUsage is the energy a request consumed
+type EnergyUsage struct {
+	Source         string  `json:"source"` // "rapl", "amd_energy" or "tdp-estimate"
+	Joules         float64 `json:"joules"`
+	JoulesPerToken float64 `json:"joules_per_token"` // per output token; 0 when the backend didn't count them
+	AvgWatts       float64 `json:"avg_watts"`
+}
+
+// EnergyCost projects a model's electricity use onto 1,000 captures, one
+// capture being an average run of the scenarios
+type EnergyCost struct {
+	Model          string  `json:"model"`
+	Source         string  `json:"source"`
+	AvgJoules      float64 `json:"avg_joules"`
+	JoulesPerToken float64 `json:"joules_per_token"`
+	AvgWatts       float64 `json:"avg_watts"`
+	KWhPer1000     float64 `json:"kwh_per_1000_captures"`
+	CostPer1000    float64 `json:"cost_per_1000_captures"` // at BenchmarkSummary.KWhPrice
+}
+
+// defaultKWhPrice is the electricity price used without --kwh-price
+const defaultKWhPrice = 0.15
+
+// energyProbe reads the energy counters. Multi-host runs replace it while
+// benchmarking a remote host, whose counters can't be seen from here.
+var energyProbe = func() (energySnapshot, bool) { return readEnergyCounters("/sys") }
+
+// energySnapshot holds cumulative microjoules per counter file
+type energySnapshot struct {
+	source   string
+	counters map[string]uint64
+	ranges   map[string]uint64 // wraparound point, where the counter reports one
+}
+
+// measureEnergy reads the counters now. The returned func reads them again
+// and reports the energy used over a run of the given length, or nil when
+// the counters can't be read; they are often root-only.
+func measureEnergy() func(time.Duration) *EnergyUsage {
+	before, ok := energyProbe()
+	if !ok {
+		return func(time.Duration) *EnergyUsage { return nil }
+	}
+
+	return func(elapsed time.Duration) *EnergyUsage {
+		after, ok := energyProbe()
+		if !ok || elapsed <= 0 {
+			return nil
+		}
+
+		var microjoules uint64
+		for path, end := range after.counters {
+			start, seen := before.counters[path]
+			if !seen {
+				continue
+			}
+			if end < start {
+				// The counter wrapped; without a known range the run is lost
+				if after.ranges[path] == 0 {
+					return nil
+				}
+				end += after.ranges[path]
+			}
+			microjoules += end - start
+		}
+
+		joules := float64(microjoules) / 1e6
+		return &EnergyUsage{Source: after.source, Joules: joules, AvgWatts: joules / elapsed.Seconds()}
+	}
+}
+
+// estimateEnergy stands in for the counters: the device's TDP scaled by how
+// busy the CPU was, or the full TDP when CPU usage wasn't sampled. It
+// returns nil without a TDP.
+func estimateEnergy(elapsed time.Duration, cpu *CPUUsage, tdpWatts float64) *EnergyUsage {
+	if tdpWatts <= 0 || elapsed <= 0 {
+		return nil
+	}
+	watts := tdpWatts
+	if cpu != nil {
+		watts = tdpWatts * cpu.AvgPercent / 100
+	}
+	return &EnergyUsage{Source: "tdp-estimate", Joules: watts * elapsed.Seconds(), AvgWatts: watts}
+}
+
+// readEnergyCounters reads the package counters under sysRoot. Powercap's
+// intel-rapl zones, which recent kernels also register for AMD Zen, come
+// first; otherwise the amd_energy hwmon driver's socket counters.
+func readEnergyCounters(sysRoot string) (energySnapshot, bool) {
+	snapshot := energySnapshot{counters: make(map[string]uint64), ranges: make(map[string]uint64)}
+
+	// Top-level zones only: subzones (core, uncore, dram) are already part of
+	// their package. psys covers the whole platform, so it's only used when
+	// there is no package zone, never added to one.
+	zones, _ := filepath.Glob(filepath.Join(sysRoot, "class", "powercap", "intel-rapl:*"))
+	var packages, platform []string
+	for _, zone := range zones {
+		if strings.Count(filepath.Base(zone), ":") != 1 {
+			continue
+		}
+		switch name := readSysString(filepath.Join(zone, "name")); {
+		case strings.HasPrefix(name, "package"):
+			packages = append(packages, zone)
+		case name == "psys":
+			platform = append(platform, zone)
+		}
+	}
+	if len(packages) == 0 {
+		packages = platform
+	}
+	for _, zone := range packages {
+		value, ok := readSysInt(filepath.Join(zone, "energy_uj"))
+		if !ok {
+			continue
+		}
+		path := filepath.Join(zone, "energy_uj")
+		snapshot.counters[path] = uint64(value)
+		if limit, ok := readSysInt(filepath.Join(zone, "max_energy_range_uj")); ok {
+			snapshot.ranges[path] = uint64(limit)
+		}
+	}
+	if len(snapshot.counters) > 0 {
+		snapshot.source = "rapl"
+		return snapshot, true
+	}
+
+	monitors, _ := filepath.Glob(filepath.Join(sysRoot, "class", "hwmon", "hwmon*"))
+	for _, monitor := range monitors {
+		if readSysString(filepath.Join(monitor, "name")) != "amd_energy" {
+			continue
+		}
+		labels, _ := filepath.Glob(filepath.Join(monitor, "energy*_label"))
+		for _, label := range labels {
+			if !strings.HasPrefix(readSysString(label), "Esocket") {
+				continue // per-core counters are part of the socket's
+			}
+			path := strings.TrimSuffix(label, "_label") + "_input"
+			if value, ok := readSysInt(path); ok {
+				snapshot.counters[path] = uint64(value)
+			}
+		}
+	}
+	if len(snapshot.counters) > 0 {
+		snapshot.source = "amd_energy"
+		return snapshot, true
+	}
+
+	return snapshot, false
+}
+
+// defaultTDPWatts guesses a device's TDP from its thermal profile, for when
+// neither energy counters nor --tdp are available
+func defaultTDPWatts(thermalProfile string) float64 {
+	switch thermalProfile {
+	case "mobile":
+		return 5
+	case "laptop":
+		return 15
+	case "desktop":
+		return 65
+	}
+	return 125
+}
+
+// summarizeEnergyCosts averages each model's measured runs and prices
+// 1,000 of them at kwhPrice
+func summarizeEnergyCosts(results []BenchmarkResult, kwhPrice float64) []EnergyCost {
+	byModel := make(map[string][]*EnergyUsage)
+	var models []string
+	for _, result := range results {
+		if !result.Success || result.Energy == nil {
+			continue
+		}
+		if _, seen := byModel[result.Model]; !seen {
+			models = append(models, result.Model)
+		}
+		byModel[result.Model] = append(byModel[result.Model], result.Energy)
+	}
+	sort.Strings(models)
+
+	var costs []EnergyCost
+	for _, model := range models {
+		usages := byModel[model]
+		cost := EnergyCost{Model: model, Source: usages[0].Source}
+		var perToken float64
+		var counted int
+		for _, usage := range usages {
+			cost.AvgJoules += usage.Joules
+			cost.AvgWatts += usage.AvgWatts
+			if usage.JoulesPerToken > 0 {
+				perToken += usage.JoulesPerToken
+				counted++
+			}
+			if usage.Source != cost.Source {
+				cost.Source = "mixed"
+			}
+		}
+		n := float64(len(usages))
+		cost.AvgJoules /= n
+		cost.AvgWatts /= n
+		if counted > 0 {
+			cost.JoulesPerToken = perToken / float64(counted)
+		}
+		cost.KWhPer1000 = cost.AvgJoules * 1000 / 3.6e6
+		cost.CostPer1000 = cost.KWhPer1000 * kwhPrice
+		costs = append(costs, cost)
+	}
+	return costs
+}
+
+// printEnergyCosts prints the electricity table
+func printEnergyCosts(costs []EnergyCost, kwhPrice float64) {
+	if len(costs) == 0 {
+		return
+	}
+
+	fmt.Printf("\n🔌 Electricity per 1,000 Captures (at %.2f/kWh):\n", kwhPrice)
+	for _, cost := range costs {
+		fmt.Printf("  %s: %.1fJ/run, %.3fJ/token, %.1fW avg → %.4fkWh, cost %.4f (%s)\n",
+			cost.Model, cost.AvgJoules, cost.JoulesPerToken, cost.AvgWatts,
+			cost.KWhPer1000, cost.CostPer1000, cost.Source)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LowSpecOptions enable the optional low-spec benchmark modes
+type LowSpecOptions struct {
+	MaxConcurrency   int           // --concurrency=N: sweep 1..N parallel requests; 0 disables the sweep
+	ContextSweep     bool          // --context-sweep[=max]: sweep num_ctx upwards from 1024
+	MaxContext       int           // 0 means the model's trained context length, or 8192 if unknown
+	LatencyBudget    time.Duration // --latency-budget=10s: response time, less model loading, a sweep step may not exceed
+	QuantizationOnly bool          // --quantization: only test models installed in several quantizations
+	HostsFile        string        // --hosts=hosts.json: benchmark every endpoint listed in the file
+	TDPWatts         float64       // --tdp=15: device TDP for energy estimates without RAPL counters; 0 guesses from the thermal profile
+	KWhPrice         float64       // --kwh-price=0.30: electricity price for the cost report
+	Root             string        // --root=DIR: profile the device captured in a snapshot directory instead of this machine
+	SnapshotDir      string        // snapshot DIR: capture this machine's /proc and /sys files into DIR and exit
+}
+
+// parseLowSpecOptions reads the low-spec switches from args, which should
+// hold what parseBackendOptions left; anything else is an error
+func parseLowSpecOptions(args []string) (LowSpecOptions, error) {
+	opts := LowSpecOptions{LatencyBudget: 10 * time.Second, KWhPrice: defaultKWhPrice}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "snapshot":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return opts, fmt.Errorf("snapshot needs a directory to write to, like snapshot fixtures/my-laptop")
+			}
+			opts.SnapshotDir = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--root="):
+			opts.Root = strings.TrimPrefix(arg, "--root=")
+		case strings.HasPrefix(arg, "--concurrency="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--concurrency="))
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("--concurrency needs a positive number, got %q", arg)
+			}
+			opts.MaxConcurrency = n
+		case arg == "--context-sweep":
+			opts.ContextSweep = true
+		case strings.HasPrefix(arg, "--context-sweep="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--context-sweep="))
+			if err != nil || n < minSweepContext {
+				return opts, fmt.Errorf("--context-sweep needs a context length of at least %d, got %q", minSweepContext, arg)
+			}
+			opts.ContextSweep = true
+			opts.MaxContext = n
+		case strings.HasPrefix(arg, "--hosts="):
+			opts.HostsFile = strings.TrimPrefix(arg, "--hosts=")
+		case arg == "--quantization":
+			opts.QuantizationOnly = true
+		case strings.HasPrefix(arg, "--latency-budget="):
+			budget, err := time.ParseDuration(strings.TrimPrefix(arg, "--latency-budget="))
+			if err != nil || budget <= 0 {
+				return opts, fmt.Errorf("--latency-budget needs a duration like 10s, got %q", arg)
+			}
+			opts.LatencyBudget = budget
+		case strings.HasPrefix(arg, "--tdp="):
+			watts, err := strconv.ParseFloat(strings.TrimPrefix(arg, "--tdp="), 64)
+			if err != nil || watts <= 0 {
+				return opts, fmt.Errorf("--tdp needs a positive wattage, got %q", arg)
+			}
+			opts.TDPWatts = watts
+		case strings.HasPrefix(arg, "--kwh-price="):
+			price, err := strconv.ParseFloat(strings.TrimPrefix(arg, "--kwh-price="), 64)
+			if err != nil || price < 0 {
+				return opts, fmt.Errorf("--kwh-price needs a price per kWh like 0.30, got %q", arg)
+			}
+			opts.KWhPrice = price
+		case strings.HasPrefix(arg, "-"):
+			return opts, fmt.Errorf("unknown option %q", arg)
+		default:
+			return opts, fmt.Errorf("unexpected argument %q; the only command is snapshot DIR", arg)
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLowSpecOptions(t *testing.T) {
+	_, args, err := parseBackendOptions([]string{"--mock", "--concurrency=4", "--context-sweep", "--latency-budget=5s", "snapshot", "snaps/pi", "--root=snaps/nuc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := parseLowSpecOptions(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.MaxConcurrency != 4 || !opts.ContextSweep || opts.LatencyBudget != 5*time.Second {
+		t.Errorf("opts = %+v", opts)
+	}
+	if opts.SnapshotDir != "snaps/pi" || opts.Root != "snaps/nuc" {
+		t.Errorf("snapshot %q, root %q", opts.SnapshotDir, opts.Root)
+	}
+}
+
+func TestParseLowSpecOptionsRejectsUnknown(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--concurency=4"}, `unknown option "--concurency=4"`},
+		{[]string{"--quantization", "-v"}, `unknown option "-v"`},
+		// Backend flags are parsed first and never get here
+		{[]string{"--mock"}, `unknown option "--mock"`},
+		{[]string{"llama3.2:3b"}, `unexpected argument "llama3.2:3b"`},
+		{[]string{"snapshot"}, "snapshot needs a directory"},
+		{[]string{"snapshot", "--root=x"}, "snapshot needs a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			_, err := parseLowSpecOptions(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
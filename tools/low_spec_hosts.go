@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HostEndpoint is one machine in a multi-host run. Remote hosts can't be
+// profiled from here, so their hardware is described in the hosts file.
+type HostEndpoint struct {
+	Name    string           `json:"name"`
+	URL     string           `json:"url"`               // Ollama endpoint, e.g. http://raspberrypi.local:11434
+	Profile *HardwareProfile `json:"profile,omitempty"` // optional for localhost, which is profiled directly
+}
+
+// HostBenchmark is the full low-spec benchmark of one host
+type HostBenchmark struct {
+	Host      string           `json:"host"`
+	URL       string           `json:"url"`
+	Error     string           `json:"error,omitempty"`
+	Benchmark LowSpecBenchmark `json:"benchmark"`
+}
+
+// HostComparison is one row of the per-host comparison table
+type HostComparison struct {
+	Host                string        `json:"host"`
+	ThermalProfile      string        `json:"thermal_profile"`
+	CPUCores            int           `json:"cpu_cores"`
+	TotalRAM            int64         `json:"total_ram_mb"`
+	ModelsTested        int           `json:"models_tested"`
+	SuccessRate         float64       `json:"success_rate"`
+	AvgResponseTime     time.Duration `json:"avg_response_time"`
+	AvgTimeToFirstToken time.Duration `json:"avg_time_to_first_token"`
+	AvgTokensPerSecond  float64       `json:"avg_tokens_per_second"`
+	PrimaryModel        string        `json:"primary_model"`
+}
+
+// MultiHostBenchmark merges the benchmarks of several hosts
+type MultiHostBenchmark struct {
+	Hosts       []HostBenchmark               `json:"hosts"`
+	Comparison  []HostComparison              `json:"comparison"`
+	ModelSpeeds map[string]map[string]float64 `json:"model_speeds"` // model -> host -> average tokens/s
+	Timestamp   time.Time                     `json:"timestamp"`
+}
+
+// loadHostEndpoints reads the hosts file: a JSON list of endpoints
+func loadHostEndpoints(path string) ([]HostEndpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []HostEndpoint
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%s lists no hosts", path)
+	}
+
+	seen := make(map[string]bool)
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if endpoint.URL == "" {
+			return nil, fmt.Errorf("%s: host %d has no url", path, i+1)
+		}
+		endpoint.URL = normalizeOllamaURL(endpoint.URL)
+		if endpoint.Name == "" {
+			endpoint.Name = strings.TrimPrefix(strings.TrimPrefix(endpoint.URL, "http://"), "https://")
+		}
+		if seen[endpoint.Name] {
+			return nil, fmt.Errorf("%s: host name %q is used twice", path, endpoint.Name)
+		}
+		seen[endpoint.Name] = true
+	}
+
+	return endpoints, nil
+}
+
+// hardwareProfile returns the endpoint's profile, profiling localhost when
+// the hosts file doesn't describe it
+func (e HostEndpoint) hardwareProfile() HardwareProfile {
+	if e.Profile == nil {
+		if isLocalURL(e.URL) {
+			if profile, err := profileHardware("/"); err == nil {
+				profile.DeviceName = e.Name
+				return profile
+			}
+		}
+		return HardwareProfile{DeviceName: e.Name, Notes: "not profiled; add a profile to the hosts file"}
+	}
+
+	profile := *e.Profile
+	if profile.DeviceName == "" {
+		profile.DeviceName = e.Name
+	}
+	if profile.ThermalProfile == "" {
+		profile.ThermalProfile = inferThermalProfile(profile)
+	}
+	if profile.PowerProfile == "" {
+		profile.PowerProfile = inferPowerProfile(profile)
+	}
+	return profile
+}
+
+// runMultiHostBenchmark benchmarks each endpoint in turn and merges the
+// results into one file with a per-host comparison
+func runMultiHostBenchmark(opts BackendOptions, modes LowSpecOptions) {
+	endpoints, err := loadHostEndpoints(modes.HostsFile)
+	if err != nil {
+		log.Fatalf("Failed to load hosts: %v", err)
+	}
+
+	multi := MultiHostBenchmark{Timestamp: time.Now()}
+	for _, endpoint := range endpoints {
+		fmt.Printf("\n🖥️  Host %s (%s)\n", endpoint.Name, endpoint.URL)
+		fmt.Println("-----------------------------------")
+		multi.Hosts = append(multi.Hosts, runHostBenchmark(opts, modes, endpoint))
+	}
+
+	multi.Comparison = compareHosts(multi.Hosts)
+	multi.ModelSpeeds = modelSpeedsByHost(multi.Hosts)
+
+	outputPath := fmt.Sprintf("results/low_spec_multihost_%s.json", time.Now().Format("2006-01-02_15-04-05"))
+	if err := saveMultiHostBenchmark(multi, outputPath); err != nil {
+		log.Printf("Failed to save benchmark: %v", err)
+	} else {
+		fmt.Printf("\n💾 Results saved to: %s\n", outputPath)
+	}
+
+	printHostComparison(multi)
+}
+
+func runHostBenchmark(opts BackendOptions, modes LowSpecOptions, endpoint HostEndpoint) HostBenchmark {
+	host := HostBenchmark{Host: endpoint.Name, URL: endpoint.URL}
+
+	// Each host records to (or replays from) its own cassette
+	if opts.Record != "" {
+		opts.Record = hostCassettePath(opts.Record, endpoint.Name)
+	}
+	if opts.Replay != "" {
+		opts.Replay = hostCassettePath(opts.Replay, endpoint.Name)
+	}
+
+	profile := endpoint.hardwareProfile()
+	printHardwareProfile(profile)
+
+	backend, finish, err := opts.apply(NewOllamaBackend(endpoint.URL))
+	if err != nil {
+		host.Error = err.Error()
+		fmt.Printf("❌ %s\n", host.Error)
+		return host
+	}
+
+	// Memory and CPU readings only mean something for the machine we run on
+	if !isLocalURL(endpoint.URL) {
+		defer disableLocalProbes()()
+	}
+
+	host.Benchmark, err = runBenchmark(backend, profile, modes)
+	if err != nil {
+		host.Error = err.Error()
+		fmt.Printf("❌ %s\n", host.Error)
+	}
+
+	if err := finish(); err != nil {
+		log.Printf("Failed to save cassette: %v", err)
+	} else if opts.Record != "" {
+		fmt.Printf("📼 Cassette saved to: %s\n", opts.Record)
+	}
+
+	return host
+}
+
+// disableLocalProbes stops the resource probes from reading this machine
+// and returns a func that puts back the probes it replaced
+func disableLocalProbes() func() {
+	memory, process, cpu, pressure := memoryProbe, processMemoryProbe, cpuProbe, pressureProbe
+	thermal, power, energy := thermalProbe, powerProbe, energyProbe
+
+	memoryProbe = func() int64 { return 0 }
+	processMemoryProbe = func() (ProcessMemorySample, bool) { return ProcessMemorySample{}, false }
+	cpuProbe = func() (cpuSnapshot, bool) { return cpuSnapshot{}, false }
+	pressureProbe = func() (pressureCounters, bool) { return pressureCounters{}, false }
+	thermalProbe = func() (thermalSnapshot, bool) { return thermalSnapshot{}, false }
+	powerProbe = func() (PowerStatus, bool) { return PowerStatus{}, false }
+	energyProbe = func() (energySnapshot, bool) { return energySnapshot{}, false }
+
+	return func() {
+		memoryProbe, processMemoryProbe, cpuProbe, pressureProbe = memory, process, cpu, pressure
+		thermalProbe, powerProbe, energyProbe = thermal, power, energy
+	}
+}
+
+// hostCassettePath turns cassette.json into cassette.<host>.json
+func hostCassettePath(path, host string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + sanitizeDeviceName(host) + ext
+}
+
+func compareHosts(hosts []HostBenchmark) []HostComparison {
+	var comparison []HostComparison
+
+	for _, host := range hosts {
+		benchmark := host.Benchmark
+		row := HostComparison{
+			Host:           host.Host,
+			ThermalProfile: benchmark.DeviceProfile.ThermalProfile,
+			CPUCores:       benchmark.DeviceProfile.CPUCores,
+			TotalRAM:       benchmark.DeviceProfile.TotalRAM,
+			PrimaryModel:   benchmark.Summary.RecommendedConfig.PrimaryModel,
+		}
+
+		models := make(map[string]bool)
+		var successes int
+		var totalTime, totalTTFT time.Duration
+		var totalSpeed float64
+		for _, result := range benchmark.TestResults {
+			models[result.Model] = true
+			if !result.Success {
+				continue
+			}
+			successes++
+			totalTime += result.ResponseTime
+			totalTTFT += result.TimeToFirstToken
+			totalSpeed += result.TokensPerSecond
+		}
+
+		row.ModelsTested = len(models)
+		if len(benchmark.TestResults) > 0 {
+			row.SuccessRate = float64(successes) / float64(len(benchmark.TestResults))
+		}
+		if successes > 0 {
+			row.AvgResponseTime = totalTime / time.Duration(successes)
+			row.AvgTimeToFirstToken = totalTTFT / time.Duration(successes)
+			row.AvgTokensPerSecond = totalSpeed / float64(successes)
+		}
+
+		comparison = append(comparison, row)
+	}
+
+	return comparison
+}
+
+func modelSpeedsByHost(hosts []HostBenchmark) map[string]map[string]float64 {
+	totals := make(map[string]map[string]float64)
+	counts := make(map[string]map[string]int)
+
+	for _, host := range hosts {
+		for _, result := range host.Benchmark.TestResults {
+			if !result.Success {
+				continue
+			}
+			if totals[result.Model] == nil {
+				totals[result.Model] = make(map[string]float64)
+				counts[result.Model] = make(map[string]int)
+			}
+			totals[result.Model][host.Host] += result.TokensPerSecond
+			counts[result.Model][host.Host]++
+		}
+	}
+
+	for model, byHost := range totals {
+		for host := range byHost {
+			byHost[host] /= float64(counts[model][host])
+		}
+	}
+	return totals
+}
+
+func saveMultiHostBenchmark(multi MultiHostBenchmark, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(multi, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func printHostComparison(multi MultiHostBenchmark) {
+	fmt.Println("\n🖥️  HOST COMPARISON")
+	fmt.Println("===================")
+
+	fmt.Printf("\n%-16s %-8s %5s %8s %6s %8s %9s %9s %8s  %s\n",
+		"Host", "Class", "Cores", "RAM(MB)", "Models", "Success", "Response", "TTFT", "Tok/s", "Primary Model")
+	for i, row := range multi.Comparison {
+		if multi.Hosts[i].Error != "" {
+			fmt.Printf("%-16s ❌ %s\n", row.Host, multi.Hosts[i].Error)
+			continue
+		}
+		fmt.Printf("%-16s %-8s %5d %8d %6d %7.0f%% %9v %9v %8.1f  %s\n",
+			row.Host, row.ThermalProfile, row.CPUCores, row.TotalRAM,
+			row.ModelsTested, row.SuccessRate*100,
+			row.AvgResponseTime.Round(time.Millisecond),
+			row.AvgTimeToFirstToken.Round(time.Millisecond),
+			row.AvgTokensPerSecond, row.PrimaryModel)
+	}
+
+	if len(multi.ModelSpeeds) == 0 {
+		return
+	}
+
+	var models []string
+	for model := range multi.ModelSpeeds {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	fmt.Println("\n⚡ Tokens/s by Model and Host:")
+	fmt.Printf("%-24s", "Model")
+	for _, host := range multi.Hosts {
+		fmt.Printf(" %12s", host.Host)
+	}
+	fmt.Println()
+	for _, model := range models {
+		fmt.Printf("%-24s", model)
+		for _, host := range multi.Hosts {
+			if speed, ok := multi.ModelSpeeds[model][host.Host]; ok {
+				fmt.Printf(" %12.1f", speed)
+			} else {
+				fmt.Printf(" %12s", "-")
+			}
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// hostStub stubs an Ollama server with one installed model. Replies stream
+// as NDJSON and report 20 tokens decoded in decode.
+func hostStub(t *testing.T, listen string, decode time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&body)
+		}
+		done := fmt.Sprintf(`"done":true,"prompt_eval_count":12,"eval_count":20,"eval_duration":%d`, decode.Nanoseconds())
+
+		switch r.URL.Path {
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"tiny:latest","size":700000000,"details":{"parameter_size":"1.1B","quantization_level":"Q4_0"}}]}`)
+		case "/api/generate":
+			// Unloading is a generate call with keep_alive=0
+			if _, ok := body["keep_alive"]; ok {
+				fmt.Fprint(w, `{"done":true}`)
+				return
+			}
+			for _, piece := range []string{"Fixed the login ", "bug in the user service."} {
+				fmt.Fprintf(w, "{\"response\":%q}\n", piece)
+				w.(http.Flusher).Flush()
+			}
+			fmt.Fprintf(w, "{\"response\":\"\",%s}\n", done)
+		case "/api/chat":
+			fmt.Fprintf(w, "{\"message\":{\"role\":\"assistant\",\"content\":\"Fixed the login bug in the user service.\"},%s}\n", done)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		t.Skipf("can't listen on %s: %v", listen, err)
+	}
+	srv.Listener = listener
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDisableLocalProbesRestoresPrevious(t *testing.T) {
+	t.Cleanup(disableLocalProbes())
+	processMemoryProbe = func() (ProcessMemorySample, bool) { return ProcessMemorySample{RSSMB: 900}, true }
+
+	restore := disableLocalProbes()
+	if _, ok := processMemoryProbe(); ok {
+		t.Error("process memory is still read while disabled")
+	}
+	restore()
+	if sample, ok := processMemoryProbe(); !ok || sample.RSSMB != 900 {
+		t.Errorf("restored probe read %+v, %v; want the stub back", sample, ok)
+	}
+}
+
+func TestRunHostBenchmark(t *testing.T) {
+	t.Cleanup(disableLocalProbes())
+	processMemoryProbe = func() (ProcessMemorySample, bool) { return ProcessMemorySample{RSSMB: 900}, true }
+	pause := recoveryPause
+	recoveryPause = 0
+	t.Cleanup(func() { recoveryPause = pause })
+
+	profile := &HardwareProfile{CPUCores: 4, TotalRAM: 4096, AvailableRAM: 2048}
+	local := hostStub(t, "127.0.0.1:0", time.Second)
+	// Any loopback address but 127.0.0.1 stands in for another machine
+	remote := hostStub(t, "127.0.0.2:0", 2*time.Second)
+	gone := httptest.NewServer(http.NotFoundHandler())
+	gone.Close()
+
+	var hosts []HostBenchmark
+	for _, endpoint := range []HostEndpoint{
+		{Name: "laptop", URL: local.URL, Profile: profile},
+		{Name: "pi", URL: remote.URL, Profile: profile},
+		{Name: "offline", URL: gone.URL, Profile: profile},
+	} {
+		hosts = append(hosts, runHostBenchmark(BackendOptions{}, LowSpecOptions{}, endpoint))
+	}
+
+	scenarios := len(getLowSpecTestScenarios())
+	for _, host := range hosts[:2] {
+		if host.Error != "" || len(host.Benchmark.TestResults) != scenarios || len(host.Benchmark.ColdStarts) != 1 {
+			t.Fatalf("%s: error %q, %d results, %d cold starts", host.Host, host.Error, len(host.Benchmark.TestResults), len(host.Benchmark.ColdStarts))
+		}
+		for _, result := range host.Benchmark.TestResults {
+			if !result.Success {
+				t.Errorf("%s %s: %s", host.Host, result.TestCase, result.Error)
+			}
+		}
+	}
+	if hosts[2].Error == "" {
+		t.Error("an unreachable host reported no error")
+	}
+
+	// Only the local host's runtime is sampled, and the stub is back afterwards
+	if memory := hosts[0].Benchmark.TestResults[0].ProcessMemory; memory == nil || memory.PeakMB != 900 {
+		t.Errorf("local process memory = %+v", memory)
+	}
+	if memory := hosts[1].Benchmark.TestResults[0].ProcessMemory; memory != nil {
+		t.Errorf("remote process memory = %+v", memory)
+	}
+	if _, ok := processMemoryProbe(); !ok {
+		t.Error("the process memory probe wasn't restored")
+	}
+
+	comparison := compareHosts(hosts)
+	if len(comparison) != 3 {
+		t.Fatalf("comparison = %+v", comparison)
+	}
+	for i, want := range []float64{20, 10} {
+		row := comparison[i]
+		if row.ModelsTested != 1 || row.SuccessRate != 1 || math.Abs(row.AvgTokensPerSecond-want) > 1e-9 {
+			t.Errorf("%s = %+v", row.Host, row)
+		}
+		if row.PrimaryModel != "tiny:latest" || row.CPUCores != 4 || row.AvgResponseTime <= 0 {
+			t.Errorf("%s = %+v", row.Host, row)
+		}
+	}
+	if offline := comparison[2]; offline.ModelsTested != 0 || offline.SuccessRate != 0 || offline.AvgTokensPerSecond != 0 {
+		t.Errorf("offline = %+v", offline)
+	}
+
+	speeds := modelSpeedsByHost(hosts)
+	if len(speeds) != 1 || speeds["tiny:latest"]["laptop"] != 20 || speeds["tiny:latest"]["pi"] != 10 {
+		t.Errorf("model speeds = %v", speeds)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PowerStatus is the device's power source at one moment, from
+// /sys/class/power_supply
+type PowerStatus struct {
+	ACOnline      bool    `json:"ac_online"`      // a mains or USB supply is connected
+	Battery       bool    `json:"battery"`        // a system battery is present
+	OnBattery     bool    `json:"on_battery"`     // running from the battery right now
+	Status        string  `json:"status"`         // the battery's "Charging", "Discharging", "Full", ...
+	ChargePercent float64 `json:"charge_percent"` // average over the batteries
+	DischargeW    float64 `json:"discharge_w"`    // draw from the battery; 0 unless discharging or not reported
+	EnergyWh      float64 `json:"energy_wh"`      // energy left, where reported
+}
+
+// PowerUsage is the power source before and after a request
+type PowerUsage struct {
+	Before      PowerStatus `json:"before"`
+	After       PowerStatus `json:"after"`
+	EstimatedWh float64     `json:"estimated_wh"` // average discharge over the run; 0 on AC or when not reported
+}
+
+// powerProbe reads the power supplies. Multi-host runs replace it while
+// benchmarking a remote host, whose battery can't be seen from here.
+var powerProbe = func() (PowerStatus, bool) { return readPowerStatus("/sys") }
+
+// measurePower reads the power supplies now. The returned func reads them
+// again after a run of the given length, or returns nil without supplies.
+func measurePower() func(time.Duration) *PowerUsage {
+	before, ok := powerProbe()
+	if !ok {
+		return func(time.Duration) *PowerUsage { return nil }
+	}
+
+	return func(elapsed time.Duration) *PowerUsage {
+		after, ok := powerProbe()
+		if !ok {
+			return nil
+		}
+		usage := &PowerUsage{Before: before, After: after}
+		if before.OnBattery && after.OnBattery {
+			usage.EstimatedWh = (before.DischargeW + after.DischargeW) / 2 * elapsed.Hours()
+		}
+		return usage
+	}
+}
+
+// readPowerStatus reads every supply under sysRoot. Peripheral batteries
+// (mice, headsets) report scope "Device" and are skipped. An empty
+// power_supply class, as on VMs and desktops, means mains power without a
+// battery; it returns false only when the class is missing altogether.
+func readPowerStatus(sysRoot string) (PowerStatus, bool) {
+	var status PowerStatus
+	class := filepath.Join(sysRoot, "class", "power_supply")
+	if _, err := os.Stat(class); err != nil {
+		return status, false
+	}
+	supplies, _ := filepath.Glob(filepath.Join(class, "*"))
+
+	var batteries int
+	var charge float64
+	discharging := false
+	for _, supply := range supplies {
+		if readSysString(filepath.Join(supply, "scope")) == "Device" {
+			continue
+		}
+
+		switch readSysString(filepath.Join(supply, "type")) {
+		case "Mains", "USB":
+			if online, ok := readSysInt(filepath.Join(supply, "online")); ok && online == 1 {
+				status.ACOnline = true
+			}
+		case "Battery":
+			if present, ok := readSysInt(filepath.Join(supply, "present")); ok && present == 0 {
+				continue
+			}
+			batteries++
+			status.Battery = true
+			status.Status = readSysString(filepath.Join(supply, "status"))
+			if status.Status == "Discharging" {
+				discharging = true
+			}
+			if capacity, ok := readSysInt(filepath.Join(supply, "capacity")); ok {
+				charge += float64(capacity)
+			}
+			watts, wattHours := batteryPower(supply)
+			if status.Status == "Discharging" {
+				status.DischargeW += watts
+			}
+			status.EnergyWh += wattHours
+		}
+	}
+
+	if batteries > 0 {
+		status.ChargePercent = charge / float64(batteries)
+	}
+	status.OnBattery = discharging && !status.ACOnline
+	return status, true
+}
+
+// batteryPower reads a battery's draw and remaining energy. Drivers report
+// either power and energy (µW, µWh) or current and charge (µA, µAh), which
+// need the voltage (µV) to convert.
+func batteryPower(supply string) (float64, float64) {
+	var watts, wattHours float64
+	voltage, hasVoltage := readSysInt(filepath.Join(supply, "voltage_now"))
+
+	if power, ok := readSysInt(filepath.Join(supply, "power_now")); ok {
+		watts = float64(power) / 1e6
+	} else if current, ok := readSysInt(filepath.Join(supply, "current_now")); ok && hasVoltage {
+		watts = float64(current) * float64(voltage) / 1e12
+	}
+	if energy, ok := readSysInt(filepath.Join(supply, "energy_now")); ok {
+		wattHours = float64(energy) / 1e6
+	} else if charge, ok := readSysInt(filepath.Join(supply, "charge_now")); ok && hasVoltage {
+		wattHours = float64(charge) * float64(voltage) / 1e12
+	}
+
+	// Some drivers report discharge as a negative current
+	if watts < 0 {
+		watts = -watts
+	}
+	return watts, wattHours
+}
+
+// powerProfile maps the status onto the profile names used elsewhere
+func (s PowerStatus) powerProfile() string {
+	if s.OnBattery {
+		return "battery"
+	}
+	return "plugged"
+}
+
+// formatPowerUsage summarizes a run for progress output, or returns "" on AC
+func formatPowerUsage(usage *PowerUsage) string {
+	if !usage.Before.OnBattery && !usage.After.OnBattery {
+		return ""
+	}
+	summary := fmt.Sprintf("battery %.0f%%→%.0f%%", usage.Before.ChargePercent, usage.After.ChargePercent)
+	if usage.EstimatedWh > 0 {
+		summary += fmt.Sprintf(", %.1fW, ~%.2fWh", (usage.Before.DischargeW+usage.After.DischargeW)/2, usage.EstimatedWh)
+	}
+	return summary
+}
+
+// readSysString reads a one-line sysfs file
+func readSysString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
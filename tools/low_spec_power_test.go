@@ -0,0 +1,112 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReadPowerStatus(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		readable bool
+		want     PowerStatus
+		profile  string
+	}{
+		{"power-mains", true, PowerStatus{ACOnline: true}, "plugged"},
+		// The mouse's battery is a peripheral and doesn't count
+		{"power-battery-power-now", true, PowerStatus{
+			Battery: true, OnBattery: true, Status: "Discharging",
+			ChargePercent: 80, DischargeW: 12, EnergyWh: 40,
+		}, "battery"},
+		// 1.5A at 11.4V and 3Ah at 11.4V
+		{"power-battery-current-now", true, PowerStatus{
+			Battery: true, OnBattery: true, Status: "Discharging",
+			ChargePercent: 45, DischargeW: 17.1, EnergyWh: 34.2,
+		}, "battery"},
+		{"power-empty", true, PowerStatus{}, "plugged"},
+		{"power-desktop-vm", false, PowerStatus{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			status, ok := readPowerStatus(fixtureSysfs(tt.fixture))
+			if ok != tt.readable {
+				t.Fatalf("readable = %v, want %v", ok, tt.readable)
+			}
+			// Watts and watt-hours come out of float conversions
+			if math.Abs(status.DischargeW-tt.want.DischargeW) < 1e-9 && math.Abs(status.EnergyWh-tt.want.EnergyWh) < 1e-9 {
+				status.DischargeW, status.EnergyWh = tt.want.DischargeW, tt.want.EnergyWh
+			}
+			if status != tt.want {
+				t.Errorf("status = %+v, want %+v", status, tt.want)
+			}
+			if ok && status.powerProfile() != tt.profile {
+				t.Errorf("profile = %q, want %q", status.powerProfile(), tt.profile)
+			}
+		})
+	}
+}
+
+// replayPower reads the fixture on every probe
+func replayPower(t *testing.T, fixture string) {
+	t.Helper()
+	original := powerProbe
+	t.Cleanup(func() { powerProbe = original })
+	powerProbe = func() (PowerStatus, bool) { return readPowerStatus(fixtureSysfs(fixture)) }
+}
+
+func TestBatteryImpactFromPowerFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		elapsed time.Duration
+		wh      float64
+		impact  string
+	}{
+		{"power-mains", 30 * time.Second, 0, "n/a"},
+		// 12W for 30s is 0.1Wh
+		{"power-battery-power-now", 30 * time.Second, 0.1, "moderate"},
+		// 17.1W for 10s is 0.0475Wh
+		{"power-battery-current-now", 10 * time.Second, 0.0475, "minimal"},
+		{"power-battery-current-now", 60 * time.Second, 0.285, "high"},
+		{"power-empty", 30 * time.Second, 0, "n/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			replayPower(t, tt.fixture)
+			usage := measurePower()(tt.elapsed)
+			if usage == nil {
+				t.Fatal("no power usage")
+			}
+			if math.Abs(usage.EstimatedWh-tt.wh) > 1e-9 {
+				t.Errorf("estimated = %vWh, want %vWh", usage.EstimatedWh, tt.wh)
+			}
+			// Response time and memory only matter without a measurement
+			if impact := assessBatteryImpact(tt.elapsed, 8000, usage, "battery"); impact != tt.impact {
+				t.Errorf("impact = %q, want %q", impact, tt.impact)
+			}
+		})
+	}
+}
+
+func TestBatteryImpactWithoutPowerSupply(t *testing.T) {
+	replayPower(t, "power-desktop-vm")
+	if usage := measurePower()(30 * time.Second); usage != nil {
+		t.Fatalf("usage = %+v", usage)
+	}
+
+	// Without readings the profile falls back to the thermal profile
+	profile := HardwareProfile{ThermalProfile: "desktop"}
+	profile.PowerProfile = inferPowerProfile(profile)
+	if profile.PowerProfile != "plugged" {
+		t.Errorf("profile = %q", profile.PowerProfile)
+	}
+	if impact := assessBatteryImpact(30*time.Second, 8000, nil, profile.PowerProfile); impact != "n/a" {
+		t.Errorf("impact = %q", impact)
+	}
+	// A laptop is assumed to be on battery and rated by the heuristic
+	if impact := assessBatteryImpact(12*time.Second, 2000, nil, "battery"); impact != "moderate" {
+		t.Errorf("heuristic impact = %q", impact)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MemoryPressure is the swap traffic and memory stalls the kernel counted
+// while a request ran
+type MemoryPressure struct {
+	SwapInMB         float64 `json:"swap_in_mb"`  // pswpin
+	SwapOutMB        float64 `json:"swap_out_mb"` // pswpout
+	MajorFaults      int64   `json:"major_faults"`
+	PSI              bool    `json:"psi"`                // the kernel reports pressure stall information
+	SomeStallPercent float64 `json:"some_stall_percent"` // time at least one task waited on memory
+	FullStallPercent float64 `json:"full_stall_percent"` // time every non-idle task waited on memory
+}
+
+// pressureProbe reads the kernel's memory counters. Multi-host runs replace
+// it while benchmarking a remote host, whose kernel can't be seen from here.
+var pressureProbe = func() (pressureCounters, bool) { return readPressureCounters("/proc") }
+
+// pressureCounters are the cumulative values read before and after a run
+type pressureCounters struct {
+	swapIn, swapOut, majorFaults int64 // pages and faults from /proc/vmstat
+	psi                          bool
+	someStall, fullStall         int64 // microseconds from /proc/pressure/memory
+	at                           time.Time
+}
+
+// measureMemoryPressure reads the counters now. The returned func reads them
+// again and reports the difference, or nil if /proc/vmstat can't be read.
+func measureMemoryPressure() func() *MemoryPressure {
+	before, ok := pressureProbe()
+	if !ok {
+		return func() *MemoryPressure { return nil }
+	}
+
+	return func() *MemoryPressure {
+		after, ok := pressureProbe()
+		if !ok {
+			return nil
+		}
+
+		pageMB := float64(os.Getpagesize()) / (1024 * 1024)
+		pressure := &MemoryPressure{
+			SwapInMB:    float64(after.swapIn-before.swapIn) * pageMB,
+			SwapOutMB:   float64(after.swapOut-before.swapOut) * pageMB,
+			MajorFaults: after.majorFaults - before.majorFaults,
+			PSI:         before.psi && after.psi,
+		}
+		if elapsed := after.at.Sub(before.at).Microseconds(); pressure.PSI && elapsed > 0 {
+			pressure.SomeStallPercent = 100 * float64(after.someStall-before.someStall) / float64(elapsed)
+			pressure.FullStallPercent = 100 * float64(after.fullStall-before.fullStall) / float64(elapsed)
+		}
+		return pressure
+	}
+}
+
+// Swapped reports whether any page moved to or from swap during the run
+func (p *MemoryPressure) Swapped() bool {
+	return p.SwapInMB > 0 || p.SwapOutMB > 0
+}
+
+// OOMRisk rates the observed pressure. Stalls show the kernel already
+// struggling to find memory; swap-in shows the working set no longer fits.
+// Major faults alone aren't counted, since loading a model faults in its file.
+func (p *MemoryPressure) OOMRisk() string {
+	switch {
+	case p.FullStallPercent >= 10 || p.SomeStallPercent >= 40 || p.SwapInMB >= 256:
+		return "high"
+	case p.SomeStallPercent >= 10 || p.FullStallPercent >= 1 || p.Swapped():
+		return "medium"
+	}
+	return "low"
+}
+
+// readPressureCounters reads vmstat and, where the kernel has PSI,
+// pressure/memory under procRoot
+func readPressureCounters(procRoot string) (pressureCounters, bool) {
+	counters := pressureCounters{at: time.Now()}
+
+	data, err := os.ReadFile(filepath.Join(procRoot, "vmstat"))
+	if err != nil {
+		return counters, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "pswpin":
+			counters.swapIn = value
+		case "pswpout":
+			counters.swapOut = value
+		case "pgmajfault":
+			counters.majorFaults = value
+		}
+	}
+
+	// Lines look like "some avg10=0.00 avg60=0.00 avg300=0.00 total=12345"
+	if data, err := os.ReadFile(filepath.Join(procRoot, "pressure", "memory")); err == nil {
+		counters.psi = true
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			var total int64
+			for _, field := range fields[1:] {
+				if strings.HasPrefix(field, "total=") {
+					total, _ = strconv.ParseInt(strings.TrimPrefix(field, "total="), 10, 64)
+				}
+			}
+			switch fields[0] {
+			case "some":
+				counters.someStall = total
+			case "full":
+				counters.fullStall = total
+			}
+		}
+	}
+
+	return counters, true
+}
+
+// generatePressureRecommendations reports swapping and memory stalls seen
+// during the tests
+func generatePressureRecommendations(results []BenchmarkResult) []string {
+	var recommendations []string
+	var measured, swapped int
+	var swapIn, worstStall float64
+	for _, result := range results {
+		if result.MemoryPressure == nil {
+			continue
+		}
+		measured++
+		if result.MemoryPressure.Swapped() {
+			swapped++
+			swapIn += result.MemoryPressure.SwapInMB
+		}
+		if result.MemoryPressure.SomeStallPercent > worstStall {
+			worstStall = result.MemoryPressure.SomeStallPercent
+		}
+	}
+	if measured == 0 {
+		return nil
+	}
+
+	if swapped > 0 {
+		recommendations = append(recommendations, fmt.Sprintf("Swapped during %d/%d tests (%.0fMB read back from swap) - use a smaller model or quantization, or close other applications",
+			swapped, measured, swapIn))
+	}
+	if worstStall >= 10 {
+		recommendations = append(recommendations, fmt.Sprintf("Tasks stalled on memory up to %.0f%% of a run - this device is at its memory limit", worstStall))
+	}
+	return recommendations
+}
+
+// formatMemoryPressure summarizes a run for progress output
+func formatMemoryPressure(pressure *MemoryPressure) string {
+	summary := fmt.Sprintf("swap in %.0fMB, out %.0fMB, %d major faults", pressure.SwapInMB, pressure.SwapOutMB, pressure.MajorFaults)
+	if pressure.PSI {
+		summary += fmt.Sprintf(", memory stall %.1f%% (full %.1f%%)", pressure.SomeStallPercent, pressure.FullStallPercent)
+	}
+	return summary
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestReadPressureCounters(t *testing.T) {
+	tests := []struct {
+		fixture                      string
+		ok                           bool
+		swapIn, swapOut, majorFaults int64
+		psi                          bool
+		someStall, fullStall         int64
+	}{
+		{"ollama-runners", true, 2560, 5120, 4321, true, 1500000, 250000},
+		// Kernels without PSI still have vmstat
+		{"no-psi", true, 0, 0, 17, false, 0, 0},
+		{"no-runtime", false, 0, 0, 0, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			counters, ok := readPressureCounters(fixtureProcfs(tt.fixture))
+			if ok != tt.ok {
+				t.Fatalf("counters = %+v, %v", counters, ok)
+			}
+			if counters.swapIn != tt.swapIn || counters.swapOut != tt.swapOut || counters.majorFaults != tt.majorFaults {
+				t.Errorf("vmstat = %+v", counters)
+			}
+			if counters.psi != tt.psi || counters.someStall != tt.someStall || counters.fullStall != tt.fullStall {
+				t.Errorf("psi = %+v", counters)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProcessMemorySample is the runtime's combined footprint at one point of a request
+type ProcessMemorySample struct {
+	Elapsed   time.Duration `json:"elapsed"` // since the request started
+	RSSMB     int64         `json:"rss_mb"`
+	PSSMB     int64         `json:"pss_mb"`    // 0 unless smaps_rollup was readable for every process
+	Processes int           `json:"processes"` // server plus runners
+
+	hasPSS bool
+}
+
+// ProcessMemory summarizes the runtime's memory while a request ran. Peak
+// and mean use PSS when every sample has it, which counts pages shared
+// between the server and its runners once; otherwise RSS.
+type ProcessMemory struct {
+	Source    string                `json:"source"` // "pss" or "rss"
+	PeakMB    int64                 `json:"peak_mb"`
+	MeanMB    float64               `json:"mean_mb"`
+	PeakRSSMB int64                 `json:"peak_rss_mb"`
+	Interval  time.Duration         `json:"interval"`
+	Samples   []ProcessMemorySample `json:"samples"`
+}
+
+// processSampleInterval is how often the runtime's processes are read
+const processSampleInterval = 250 * time.Millisecond
+
+// processMemoryProbe reads the runtime's processes. Multi-host runs replace
+// it while benchmarking a remote host, whose processes can't be seen from here.
+var processMemoryProbe = func() (ProcessMemorySample, bool) { return readRuntimeProcessMemory("/proc") }
+
+// sampleProcessMemory polls the runtime's processes in the background. The
+// returned func stops polling and summarizes the samples, or returns nil if
+// no runtime process was found.
+func sampleProcessMemory() func() *ProcessMemory {
+	start := time.Now()
+	var samples []ProcessMemorySample
+	record := func() {
+		if sample, ok := processMemoryProbe(); ok {
+			sample.Elapsed = time.Since(start)
+			samples = append(samples, sample)
+		}
+	}
+
+	record()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(processSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				record()
+			}
+		}
+	}()
+
+	return func() *ProcessMemory {
+		close(stop)
+		<-done
+		record()
+		return summarizeProcessMemory(samples)
+	}
+}
+
+func summarizeProcessMemory(samples []ProcessMemorySample) *ProcessMemory {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	memory := &ProcessMemory{Source: "pss", Interval: processSampleInterval, Samples: samples}
+	for _, sample := range samples {
+		if !sample.hasPSS {
+			memory.Source = "rss"
+		}
+		if sample.RSSMB > memory.PeakRSSMB {
+			memory.PeakRSSMB = sample.RSSMB
+		}
+	}
+
+	var total int64
+	for _, sample := range samples {
+		value := sample.RSSMB
+		if memory.Source == "pss" {
+			value = sample.PSSMB
+		}
+		total += value
+		if value > memory.PeakMB {
+			memory.PeakMB = value
+		}
+	}
+	memory.MeanMB = float64(total) / float64(len(samples))
+	return memory
+}
+
+// readRuntimeProcessMemory adds up the Ollama server and runner processes
+// under procRoot. Runners come and go as models load, so it's scanned on
+// every call.
+func readRuntimeProcessMemory(procRoot string) (ProcessMemorySample, bool) {
+	var sample ProcessMemorySample
+	pids, err := runtimeProcesses(procRoot)
+	if err != nil || len(pids) == 0 {
+		return sample, false
+	}
+
+	var rssKB, pssKB int64
+	pssComplete := true
+	for _, pid := range pids {
+		dir := filepath.Join(procRoot, pid)
+		rss, ok := readProcKB(filepath.Join(dir, "status"), "VmRSS:")
+		if !ok {
+			continue // exited since the scan
+		}
+		sample.Processes++
+		rssKB += rss
+
+		// smaps_rollup needs ptrace access, which a service running as
+		// another user doesn't grant
+		pss, ok := readProcKB(filepath.Join(dir, "smaps_rollup"), "Pss:")
+		pssComplete = pssComplete && ok
+		pssKB += pss
+	}
+	if sample.Processes == 0 {
+		return sample, false
+	}
+
+	sample.RSSMB = rssKB / 1024
+	if pssComplete {
+		sample.PSSMB, sample.hasPSS = pssKB/1024, true
+	}
+	return sample, true
+}
+
+// runtimeProcesses lists the PIDs under procRoot whose command is the Ollama
+// server or one of its runners
+func runtimeProcesses(procRoot string) ([]string, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []string
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "comm"))
+		if err != nil {
+			continue
+		}
+		if isRuntimeProcess(strings.TrimSpace(string(comm))) {
+			pids = append(pids, entry.Name())
+		}
+	}
+	return pids, nil
+}
+
+// isRuntimeProcess matches the server ("ollama"), current runners (also
+// "ollama") and older releases' bundled llama.cpp server, whose name the
+// kernel truncates to 15 characters
+func isRuntimeProcess(comm string) bool {
+	return strings.HasPrefix(comm, "ollama") || comm == "llama-server"
+}
+
+// readProcKB returns the kB value of the line starting with key in a
+// /proc status-style file
+func readProcKB(path, key string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, key) {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				if val, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+					return val, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
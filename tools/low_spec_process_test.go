@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+// fixtureProcfs is a procfs tree under testdata/procfs
+func fixtureProcfs(name string) string {
+	return "testdata/procfs/" + name
+}
+
+func TestReadRuntimeProcessMemory(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		ok        bool
+		processes int
+		rssMB     int64
+		pssMB     int64
+		hasPSS    bool
+	}{
+		// The server and a runner; bash isn't the runtime
+		{"ollama-runners", true, 2, 1524, 1300, true},
+		// 102 exited after the scan, and the server's smaps_rollup wasn't readable
+		{"legacy-runner", true, 2, 700, 0, false},
+		{"no-runtime", false, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			sample, ok := readRuntimeProcessMemory(fixtureProcfs(tt.fixture))
+			if ok != tt.ok || sample.Processes != tt.processes {
+				t.Fatalf("sample = %+v, %v", sample, ok)
+			}
+			if sample.RSSMB != tt.rssMB || sample.PSSMB != tt.pssMB || sample.hasPSS != tt.hasPSS {
+				t.Errorf("sample = %+v", sample)
+			}
+		})
+	}
+}
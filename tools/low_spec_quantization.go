@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuantizationVariant is one quantization of a model, averaged over its
+// successful warm runs, with deltas against the group's reference variant
+type QuantizationVariant struct {
+	Model           string        `json:"model"`
+	Quantization    string        `json:"quantization"`
+	BitsPerWeight   float64       `json:"bits_per_weight"` // approximate, used to rank precision
+	SizeMB          int64         `json:"size_mb"`         // weights on disk, roughly what loading costs
+	Runs            int           `json:"runs"`
+	QualityScore    float64       `json:"quality_score"`
+	TokensPerSecond float64       `json:"tokens_per_second"`
+	ResponseTime    time.Duration `json:"response_time"`
+	PeakMemoryMB    int64         `json:"peak_memory_mb"` // measured while generating; 0 when no run could measure it
+
+	memoryRuns int
+
+	QualityDelta float64 `json:"quality_delta"`    // score points vs the reference
+	SpeedDelta   float64 `json:"speed_delta_pct"`  // tokens/s vs the reference
+	MemoryDelta  float64 `json:"memory_delta_pct"` // peak memory vs the reference; 0 unless both were measured
+	SizeDelta    float64 `json:"size_delta_pct"`   // size on disk vs the reference
+}
+
+// QuantizationComparison groups the variants of one model family and size.
+// The reference is the highest-precision variant that was tested.
+type QuantizationComparison struct {
+	Family        string                `json:"family"`
+	ParameterSize string                `json:"parameter_size"`
+	Reference     string                `json:"reference"`
+	Variants      []QuantizationVariant `json:"variants"`
+}
+
+// comparableQualityDelta is how many quality points a variant may lose and
+// still count as good enough
+const comparableQualityDelta = 0.25
+
+// quantizationBitsTable holds llama.cpp's approximate bits per weight
+var quantizationBitsTable = map[string]float64{
+	"F32": 32, "F16": 16, "BF16": 16,
+	"Q8_0": 8.5, "Q6_K": 6.56,
+	"Q5_1": 6, "Q5_K_M": 5.69, "Q5_K_S": 5.54, "Q5_0": 5.5,
+	"Q4_1": 5, "Q4_K_M": 4.85, "Q4_K_S": 4.58, "Q4_0": 4.5,
+	"Q3_K_L": 4.27, "Q3_K_M": 3.91, "Q3_K_S": 3.5,
+	"Q2_K": 2.63,
+}
+
+var quantizationDigits = regexp.MustCompile(`\d+`)
+
+// quantizationBits estimates bits per weight for a quantization level,
+// falling back to the first number in the name (IQ3_XXS -> 3)
+func quantizationBits(level string) float64 {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if bits, ok := quantizationBitsTable[level]; ok {
+		return bits
+	}
+	if digits := quantizationDigits.FindString(level); digits != "" {
+		bits, _ := strconv.ParseFloat(digits, 64)
+		return bits
+	}
+	return 0
+}
+
+// quantizationGroupKey identifies models that differ only in quantization
+func quantizationGroupKey(info ModelInfo) string {
+	if info.Family == "" || info.ParameterSize == "" || info.QuantizationLevel == "" {
+		return ""
+	}
+	return info.Family + " " + info.ParameterSize
+}
+
+// quantizationModels keeps the models that share their family and parameter
+// size with another installed quantization
+func quantizationModels(inventory *ModelInventory, models []string) []string {
+	levels := make(map[string]map[string]bool)
+	for _, model := range models {
+		info, ok := inventory.Lookup(model)
+		key := quantizationGroupKey(info)
+		if !ok || key == "" {
+			continue
+		}
+		if levels[key] == nil {
+			levels[key] = make(map[string]bool)
+		}
+		levels[key][info.QuantizationLevel] = true
+	}
+
+	// Tags that point at the same weights (llama3.2 and llama3.2:3b) are tested once
+	var selected []string
+	digests := make(map[string]bool)
+	for _, model := range models {
+		info, _ := inventory.Lookup(model)
+		if key := quantizationGroupKey(info); key == "" || len(levels[key]) < 2 || (info.Digest != "" && digests[info.Digest]) {
+			continue
+		}
+		digests[info.Digest] = true
+		selected = append(selected, model)
+	}
+	return selected
+}
+
+// compareQuantizations groups warm results by family and size using the
+// runtime's metadata and compares each variant against the most precise one
+func compareQuantizations(results []BenchmarkResult) []QuantizationComparison {
+	groups := make(map[string]map[string]*QuantizationVariant)
+	infos := make(map[string]ModelInfo)
+
+	for _, result := range results {
+		key := quantizationGroupKey(result.ModelInfo)
+		if !result.Success || key == "" {
+			continue
+		}
+		if groups[key] == nil {
+			groups[key] = make(map[string]*QuantizationVariant)
+		}
+		variant, ok := groups[key][result.Model]
+		if !ok {
+			variant = &QuantizationVariant{
+				Model:         result.Model,
+				Quantization:  result.ModelInfo.QuantizationLevel,
+				BitsPerWeight: quantizationBits(result.ModelInfo.QuantizationLevel),
+				SizeMB:        result.ModelInfo.SizeMB(),
+			}
+			groups[key][result.Model] = variant
+		}
+		infos[key] = result.ModelInfo
+
+		// Sums for now; averaged below
+		variant.Runs++
+		variant.QualityScore += result.QualityScore
+		variant.TokensPerSecond += result.TokensPerSecond
+		variant.ResponseTime += result.ResponseTime
+		if result.PeakMemoryMB > 0 {
+			variant.memoryRuns++
+			variant.PeakMemoryMB += result.PeakMemoryMB
+		}
+	}
+
+	var comparisons []QuantizationComparison
+	for key, variants := range groups {
+		if len(variants) < 2 {
+			continue
+		}
+
+		comparison := QuantizationComparison{
+			Family:        infos[key].Family,
+			ParameterSize: infos[key].ParameterSize,
+		}
+		for _, variant := range variants {
+			variant.QualityScore /= float64(variant.Runs)
+			variant.TokensPerSecond /= float64(variant.Runs)
+			variant.ResponseTime /= time.Duration(variant.Runs)
+			if variant.memoryRuns > 0 {
+				variant.PeakMemoryMB /= int64(variant.memoryRuns)
+			}
+			comparison.Variants = append(comparison.Variants, *variant)
+		}
+		sort.Slice(comparison.Variants, func(i, j int) bool {
+			return comparison.Variants[i].BitsPerWeight > comparison.Variants[j].BitsPerWeight
+		})
+
+		reference := comparison.Variants[0]
+		comparison.Reference = reference.Model
+		for i := range comparison.Variants {
+			variant := &comparison.Variants[i]
+			variant.QualityDelta = variant.QualityScore - reference.QualityScore
+			variant.SpeedDelta = percentChange(variant.TokensPerSecond, reference.TokensPerSecond)
+			variant.SizeDelta = percentChange(float64(variant.SizeMB), float64(reference.SizeMB))
+			if variant.PeakMemoryMB > 0 {
+				variant.MemoryDelta = percentChange(float64(variant.PeakMemoryMB), float64(reference.PeakMemoryMB))
+			}
+		}
+
+		comparisons = append(comparisons, comparison)
+	}
+
+	sort.Slice(comparisons, func(i, j int) bool {
+		if comparisons[i].Family != comparisons[j].Family {
+			return comparisons[i].Family < comparisons[j].Family
+		}
+		return comparisons[i].ParameterSize < comparisons[j].ParameterSize
+	})
+	return comparisons
+}
+
+func percentChange(value, reference float64) float64 {
+	if reference == 0 {
+		return 0
+	}
+	return (value - reference) / reference * 100
+}
+
+// generateQuantizationInsights answers "is the smaller quantization good enough?"
+func generateQuantizationInsights(comparisons []QuantizationComparison) []string {
+	var insights []string
+
+	for _, comparison := range comparisons {
+		reference := comparison.Variants[0]
+		for _, variant := range comparison.Variants[1:] {
+			verdict := "comparable quality"
+			if variant.QualityDelta < -comparableQualityDelta {
+				verdict = "noticeably lower quality"
+			}
+			memory := "memory not measured"
+			if variant.PeakMemoryMB > 0 && reference.PeakMemoryMB > 0 {
+				memory = fmt.Sprintf("%+.0f%% memory", variant.MemoryDelta)
+			}
+			insights = append(insights, fmt.Sprintf("%s %s %s vs %s: %s (%+.2f), %+.0f%% speed, %s, %+.0f%% size",
+				comparison.Family, comparison.ParameterSize, variant.Quantization, reference.Quantization, verdict,
+				variant.QualityDelta, variant.SpeedDelta, memory, variant.SizeDelta))
+		}
+	}
+
+	return insights
+}
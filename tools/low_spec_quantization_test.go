@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareQuantizationsMeasuresMemory(t *testing.T) {
+	info := func(name, level string, sizeMB int64) ModelInfo {
+		return ModelInfo{Name: name, Family: "llama", ParameterSize: "3.2B", QuantizationLevel: level, SizeBytes: sizeMB * 1024 * 1024}
+	}
+	q8, q4 := info("llama3.2:3b-q8_0", "Q8_0", 3200), info("llama3.2:3b", "Q4_K_M", 2000)
+	results := []BenchmarkResult{
+		{Model: q8.Name, ModelInfo: q8, Success: true, QualityScore: 4, TokensPerSecond: 10, PeakMemoryMB: 4000},
+		{Model: q8.Name, ModelInfo: q8, Success: true, QualityScore: 4, TokensPerSecond: 10, PeakMemoryMB: 4400},
+		{Model: q4.Name, ModelInfo: q4, Success: true, QualityScore: 3.9, TokensPerSecond: 15, PeakMemoryMB: 3150},
+		// A run that couldn't sample memory doesn't drag the average down
+		{Model: q4.Name, ModelInfo: q4, Success: true, QualityScore: 3.9, TokensPerSecond: 15},
+	}
+
+	comparisons := compareQuantizations(results)
+	if len(comparisons) != 1 || comparisons[0].Reference != q8.Name {
+		t.Fatalf("comparisons = %+v", comparisons)
+	}
+	variant := comparisons[0].Variants[1]
+	if variant.PeakMemoryMB != 3150 {
+		t.Errorf("peak memory = %dMB", variant.PeakMemoryMB)
+	}
+	// The KV cache and runtime don't shrink with the weights
+	if variant.MemoryDelta != -25 || variant.SizeDelta != -37.5 {
+		t.Errorf("memory %+.1f%%, size %+.1f%%", variant.MemoryDelta, variant.SizeDelta)
+	}
+
+	insights := generateQuantizationInsights(comparisons)
+	if len(insights) != 1 || !strings.Contains(insights[0], "-25% memory, -38% size") {
+		t.Errorf("insights = %q", insights)
+	}
+}
+
+func TestCompareQuantizationsWithoutMemory(t *testing.T) {
+	q8 := ModelInfo{Name: "a", Family: "qwen2", ParameterSize: "0.5B", QuantizationLevel: "Q8_0", SizeBytes: 500 << 20}
+	q4 := ModelInfo{Name: "b", Family: "qwen2", ParameterSize: "0.5B", QuantizationLevel: "Q4_0", SizeBytes: 400 << 20}
+	comparisons := compareQuantizations([]BenchmarkResult{
+		{Model: "a", ModelInfo: q8, Success: true, QualityScore: 3},
+		{Model: "b", ModelInfo: q4, Success: true, QualityScore: 3, PeakMemoryMB: 900},
+	})
+
+	// Without the reference's memory there's nothing to compare against
+	if variant := comparisons[0].Variants[1]; variant.MemoryDelta != 0 || variant.SizeDelta != -20 {
+		t.Errorf("variant = %+v", variant)
+	}
+	if insights := generateQuantizationInsights(comparisons); !strings.Contains(insights[0], "memory not measured") {
+		t.Errorf("insights = %q", insights)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// SnapshotInfo describes a snapshot directory: what profileHardware can't
+// read back from the captured files
+type SnapshotInfo struct {
+	DeviceName   string    `json:"device_name"`
+	OS           string    `json:"os"`
+	Architecture string    `json:"architecture"`
+	CPUCores     int       `json:"cpu_cores"` // usable by the capturing process, as runtime.NumCPU counts them
+	Files        int       `json:"files"`
+	Captured     time.Time `json:"captured"`
+}
+
+// snapshotInfoFile sits next to the captured proc and sys trees
+const snapshotInfoFile = "snapshot.json"
+
+// snapshotPatterns are the files hardware profiling reads, relative to the
+// filesystem root. Cgroup files depend on the process's cgroup and are
+// added by cgroupSnapshotFiles.
+var snapshotPatterns = []string{
+	"proc/meminfo",
+	"proc/cpuinfo",
+	"proc/self/cgroup",
+	"sys/block/*/queue/rotational",
+	"sys/devices/system/cpu/cpu[0-9]*/cpufreq/scaling_governor",
+	"sys/devices/system/cpu/cpu[0-9]*/cpufreq/scaling_driver",
+	"sys/devices/system/cpu/cpu0/cache/index*/level",
+	"sys/devices/system/cpu/cpu0/cache/index*/type",
+	"sys/devices/system/cpu/cpu0/cache/index*/size",
+	"sys/devices/system/edac/mc/mc[0-9]*/dimm[0-9]*/size",
+	"sys/devices/system/edac/mc/mc[0-9]*/dimm[0-9]*/dimm_location",
+	"sys/devices/system/edac/mc/mc[0-9]*/rank[0-9]*/size",
+	"sys/devices/system/edac/mc/mc[0-9]*/rank[0-9]*/dimm_location",
+	"sys/class/power_supply/*/type",
+	"sys/class/power_supply/*/scope",
+	"sys/class/power_supply/*/online",
+	"sys/class/power_supply/*/present",
+	"sys/class/power_supply/*/status",
+	"sys/class/power_supply/*/capacity",
+	"sys/class/power_supply/*/power_now",
+	"sys/class/power_supply/*/current_now",
+	"sys/class/power_supply/*/voltage_now",
+	"sys/class/power_supply/*/energy_now",
+	"sys/class/power_supply/*/charge_now",
+}
+
+// cgroupFiles are the limit and usage files readCgroupLimits reads, across
+// both cgroup versions
+var cgroupFiles = []string{
+	"memory.max", "memory.current", "memory.stat", "cpu.max", "cpuset.cpus.effective",
+	"memory.limit_in_bytes", "memory.usage_in_bytes", "cpu.cfs_quota_us", "cpu.cfs_period_us",
+	"cpuset.effective_cpus", "cpuset.cpus",
+}
+
+// takeSnapshot copies the files hardware profiling reads from srcRoot into
+// dir, so the device can be profiled offline with --root=dir. Files that
+// don't exist or aren't readable are left out, as profiling would skip them.
+func takeSnapshot(srcRoot, dir string) (SnapshotInfo, error) {
+	info := SnapshotInfo{
+		OS:           "linux",
+		Architecture: runtime.GOARCH,
+		CPUCores:     runtime.NumCPU(),
+		Captured:     time.Now(),
+	}
+	if _, err := os.Stat(filepath.Join(srcRoot, "proc", "meminfo")); err != nil {
+		return info, fmt.Errorf("no /proc/meminfo under %s; snapshots need a Linux /proc and /sys", srcRoot)
+	}
+	if original, err := readSnapshotInfo(srcRoot); err == nil {
+		// A snapshot of a snapshot still describes the original device
+		info.DeviceName = original.DeviceName
+		info.OS = original.OS
+		info.Architecture = original.Architecture
+		info.CPUCores = original.CPUCores
+	} else if hostname, err := os.Hostname(); err == nil {
+		info.DeviceName = hostname
+	} else {
+		info.DeviceName = "unknown"
+	}
+
+	var paths []string
+	for _, pattern := range snapshotPatterns {
+		matches, _ := filepath.Glob(filepath.Join(srcRoot, pattern))
+		paths = append(paths, matches...)
+	}
+	paths = append(paths, cgroupSnapshotFiles(srcRoot)...)
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(srcRoot, path)
+		if err != nil {
+			continue
+		}
+		target := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return info, err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return info, err
+		}
+		info.Files++
+	}
+
+	// An empty power_supply class means mains power, unlike a missing one
+	if _, err := os.Stat(filepath.Join(srcRoot, "sys", "class", "power_supply")); err == nil {
+		if err := os.MkdirAll(filepath.Join(dir, "sys", "class", "power_supply"), 0755); err != nil {
+			return info, err
+		}
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return info, err
+	}
+	return info, os.WriteFile(filepath.Join(dir, snapshotInfoFile), data, 0644)
+}
+
+// cgroupSnapshotFiles lists the cgroup files of the process's cgroup and
+// its ancestors under srcRoot
+func cgroupSnapshotFiles(srcRoot string) []string {
+	unified, v1, ok := readProcCgroup(filepath.Join(srcRoot, "proc"))
+	if !ok {
+		return nil
+	}
+
+	root := filepath.Join(srcRoot, "sys", "fs", "cgroup")
+	mounts := map[string]string{
+		root:                               unified,
+		filepath.Join(root, "memory"):      v1["memory"],
+		filepath.Join(root, "cpu"):         v1["cpu"],
+		filepath.Join(root, "cpu,cpuacct"): v1["cpu"],
+		filepath.Join(root, "cpuacct,cpu"): v1["cpu"],
+		filepath.Join(root, "cpuset"):      v1["cpuset"],
+	}
+
+	var paths []string
+	for mount, path := range mounts {
+		for _, dir := range cgroupAncestors(mount, path) {
+			for _, name := range cgroupFiles {
+				if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+					paths = append(paths, filepath.Join(dir, name))
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// readSnapshotInfo reads the description of a snapshot directory
+func readSnapshotInfo(dir string) (SnapshotInfo, error) {
+	var info SnapshotInfo
+	data, err := os.ReadFile(filepath.Join(dir, snapshotInfoFile))
+	if err != nil {
+		return info, fmt.Errorf("%s is not a snapshot directory: %w", dir, err)
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, snapshotInfoFile), err)
+	}
+	return info, nil
+}
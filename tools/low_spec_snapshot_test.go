@@ -0,0 +1,109 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fixtureSnapshot is a snapshot captured with takeSnapshot under testdata/snapshots
+func fixtureSnapshot(name string) string {
+	return "testdata/snapshots/" + name
+}
+
+func TestProfileHardwareFromSnapshot(t *testing.T) {
+	profile, err := profileHardware(fixtureSnapshot("celeron-container"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.DeviceName != "celeron-j4125" || profile.OS != "linux" || profile.CPUCores != 4 {
+		t.Errorf("device = %s %s %d cores", profile.DeviceName, profile.OS, profile.CPUCores)
+	}
+	if profile.TotalRAM != 3771 || profile.AvailableRAM != 2400 {
+		t.Errorf("RAM = %dMB total, %dMB available", profile.TotalRAM, profile.AvailableRAM)
+	}
+	// loop0 is skipped, leaving the eMMC
+	if profile.StorageType != "SSD" {
+		t.Errorf("storage = %q", profile.StorageType)
+	}
+
+	// 2GB limit with 300MB charged, 100MB of it reclaimable page cache
+	want := CgroupLimits{Version: 2, Path: "/", MemoryLimitMB: 2048, AvailableMB: 1848, CPUQuota: 1.5, CPUSetCores: 4}
+	if profile.Cgroup == nil || *profile.Cgroup != want {
+		t.Fatalf("cgroup = %+v, want %+v", profile.Cgroup, want)
+	}
+	if ram := profile.effectiveAvailableRAM(); ram != 1848 {
+		t.Errorf("effective RAM = %dMB", ram)
+	}
+
+	caps := profile.CPUCapabilities
+	if caps == nil {
+		t.Fatal("no CPU capabilities")
+	}
+	if caps.Vendor != "GenuineIntel" || caps.Model != "Intel(R) Celeron(R) J4125 CPU @ 2.00GHz" {
+		t.Errorf("cpu = %s %s", caps.Vendor, caps.Model)
+	}
+	// No AVX of any kind, so none of the SIMD paths llama.cpp cares about
+	if caps.has("avx2") || caps.has("avx") {
+		t.Errorf("features = %v", caps.Features)
+	}
+	if caps.L1dKB != 24 || caps.L2KB != 4096 || caps.L3KB != 0 || caps.MemoryChannels != 0 {
+		t.Errorf("caches = %+v", caps)
+	}
+
+	if profile.CPUGovernor == nil || profile.CPUGovernor.Driver != "intel_cpufreq" {
+		t.Errorf("governor = %+v", profile.CPUGovernor)
+	}
+	// An empty power_supply class is mains power
+	if profile.Power == nil || profile.Power.Battery {
+		t.Errorf("power = %+v", profile.Power)
+	}
+
+	config := generateRecommendedConfig(nil, profile)
+	if config.ContextLength != 2048 || config.MemoryBuffer != 1024 {
+		t.Errorf("config = %+v", config)
+	}
+	// One request at a time until a concurrency sweep says otherwise
+	if config.ConcurrentRequests != 1 || config.ConcurrencyMeasured || config.EnvironmentVars["OLLAMA_NUM_PARALLEL"] != "1" {
+		t.Errorf("concurrency = %d, measured %v, env %v", config.ConcurrentRequests, config.ConcurrencyMeasured, config.EnvironmentVars)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	fixture := fixtureSnapshot("celeron-container")
+	original, err := profileHardware(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "copy")
+	info, err := takeSnapshot(fixture, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Files != 27 {
+		t.Errorf("copied %d files", info.Files)
+	}
+	// Re-snapshotting keeps the device the snapshot was taken on
+	if info.DeviceName != "celeron-j4125" || info.CPUCores != 4 {
+		t.Errorf("info = %+v", info)
+	}
+
+	copied, err := profileHardware(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(copied, original) {
+		t.Errorf("re-profiled = %+v\nwant %+v", copied, original)
+	}
+}
+
+func TestProfileHardwareNeedsSnapshot(t *testing.T) {
+	if _, err := profileHardware(t.TempDir()); err == nil {
+		t.Error("a directory without snapshot.json should be refused")
+	}
+	if _, err := takeSnapshot(t.TempDir(), t.TempDir()); err == nil {
+		t.Error("a root without /proc should be refused")
+	}
+}
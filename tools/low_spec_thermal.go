@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ThermalReading is what the temperature sensors and cpufreq reported while
+// a request ran
+type ThermalReading struct {
+	Sensors        bool    `json:"sensors"` // thermal zones were readable
+	StartTempC     float64 `json:"start_temp_c"`
+	MaxTempC       float64 `json:"max_temp_c"`       // hottest zone during the run
+	FreqScaling    bool    `json:"freq_scaling"`     // cpufreq was readable
+	FreqSamples    int     `json:"freq_samples"`     // reads taken while the request ran; 0 for runs shorter than the interval
+	MinFreqPercent float64 `json:"min_freq_percent"` // lowest average core frequency, as a share of the cores' maximum
+	FreqDrops      int     `json:"freq_drops"`       // samples with the average core frequency below freqDropPercent
+	ThrottleEvents int64   `json:"throttle_events"`  // kernel throttle counts, or samples at a passive trip point with the clock down
+}
+
+// thermalSampleInterval is how often the sensors are read; temperatures
+// move slowly and a scan touches a file per core
+const thermalSampleInterval = time.Second
+
+// freqDropPercent is the share of maximum frequency below which a busy CPU
+// counts as clocked down
+const freqDropPercent = 90
+
+// fallbackTripC stands in for zones that don't declare a passive trip point
+const fallbackTripC = 85
+
+// thermalProbe reads the sensors. Multi-host runs replace it while
+// benchmarking a remote host, whose sensors can't be seen from here.
+var thermalProbe = func() (thermalSnapshot, bool) { return readThermalSnapshot("/sys") }
+
+// thermalSnapshot is one read of every sensor
+type thermalSnapshot struct {
+	sensors       bool
+	maxTempC      float64
+	atTrip        bool // some zone at or above its passive trip point
+	freqScaling   bool
+	freqPercent   float64 // average of scaling_cur_freq / cpuinfo_max_freq over the cores
+	counters      bool    // x86 thermal_throttle counters exist
+	throttleCount int64
+}
+
+// sampleThermal polls the sensors in the background. The returned func stops
+// polling and summarizes the run, or returns nil if neither thermal zones
+// nor cpufreq can be read.
+func sampleThermal() func() *ThermalReading {
+	first, ok := thermalProbe()
+	if !ok {
+		return func() *ThermalReading { return nil }
+	}
+
+	reading := &ThermalReading{
+		Sensors:     first.sensors,
+		StartTempC:  first.maxTempC,
+		MaxTempC:    first.maxTempC,
+		FreqScaling: first.freqScaling,
+	}
+	var samplesAtTrip int64
+	last := first
+	// The first and last reads bracket the request, when an idle CPU is
+	// clocked down anyway, so only reads in between judge the frequency
+	record := func(snapshot thermalSnapshot, running bool) {
+		if snapshot.maxTempC > reading.MaxTempC {
+			reading.MaxTempC = snapshot.maxTempC
+		}
+		if running && snapshot.freqScaling {
+			if reading.FreqSamples == 0 || snapshot.freqPercent < reading.MinFreqPercent {
+				reading.MinFreqPercent = snapshot.freqPercent
+			}
+			reading.FreqSamples++
+			if snapshot.freqPercent < freqDropPercent {
+				reading.FreqDrops++
+				if snapshot.atTrip {
+					samplesAtTrip++
+				}
+			}
+		}
+		last = snapshot
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(thermalSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if snapshot, ok := thermalProbe(); ok {
+					record(snapshot, true)
+				}
+			}
+		}
+	}()
+
+	return func() *ThermalReading {
+		close(stop)
+		<-done
+		if snapshot, ok := thermalProbe(); ok {
+			record(snapshot, false)
+		}
+
+		// The kernel's own counters are authoritative where they exist
+		if first.counters && last.counters {
+			reading.ThrottleEvents = last.throttleCount - first.throttleCount
+		} else {
+			reading.ThrottleEvents = samplesAtTrip
+		}
+		return reading
+	}
+}
+
+// readThermalSnapshot reads the thermal zones and cpufreq under sysRoot
+func readThermalSnapshot(sysRoot string) (thermalSnapshot, bool) {
+	var snapshot thermalSnapshot
+
+	zones, _ := filepath.Glob(filepath.Join(sysRoot, "class", "thermal", "thermal_zone*"))
+	for _, zone := range zones {
+		milli, ok := readSysInt(filepath.Join(zone, "temp"))
+		if !ok || milli <= 0 {
+			continue // disabled zones report errors or 0
+		}
+		temp := float64(milli) / 1000
+		snapshot.sensors = true
+		if temp > snapshot.maxTempC {
+			snapshot.maxTempC = temp
+		}
+		if temp >= passiveTripC(zone) {
+			snapshot.atTrip = true
+		}
+	}
+
+	cpus, _ := filepath.Glob(filepath.Join(sysRoot, "devices", "system", "cpu", "cpu[0-9]*"))
+	var total float64
+	var scaled int
+	for _, cpu := range cpus {
+		current, okCur := readSysInt(filepath.Join(cpu, "cpufreq", "scaling_cur_freq"))
+		maximum, okMax := readSysInt(filepath.Join(cpu, "cpufreq", "cpuinfo_max_freq"))
+		if !okMax {
+			// Some drivers only expose the policy's limit
+			maximum, okMax = readSysInt(filepath.Join(cpu, "cpufreq", "scaling_max_freq"))
+		}
+		if okCur && okMax && maximum > 0 {
+			total += 100 * float64(current) / float64(maximum)
+			scaled++
+		}
+		if count, ok := readSysInt(filepath.Join(cpu, "thermal_throttle", "core_throttle_count")); ok {
+			snapshot.counters = true
+			snapshot.throttleCount += count
+		}
+	}
+	if scaled > 0 {
+		snapshot.freqScaling = true
+		snapshot.freqPercent = total / float64(scaled)
+	}
+
+	return snapshot, snapshot.sensors || snapshot.freqScaling
+}
+
+// passiveTripC is the temperature at which the kernel starts throttling
+// the zone's cooling devices
+func passiveTripC(zone string) float64 {
+	types, _ := filepath.Glob(filepath.Join(zone, "trip_point_*_type"))
+	for _, typePath := range types {
+		data, err := os.ReadFile(typePath)
+		if err != nil || strings.TrimSpace(string(data)) != "passive" {
+			continue
+		}
+		tempPath := strings.TrimSuffix(typePath, "_type") + "_temp"
+		if milli, ok := readSysInt(tempPath); ok && milli > 0 {
+			return float64(milli) / 1000
+		}
+	}
+	return fallbackTripC
+}
+
+// CPUGovernorInfo reports the cpufreq governors and driver in use
+type CPUGovernorInfo struct {
+	Governors []string `json:"governors"` // distinct governors across cores
+	Driver    string   `json:"driver"`
+}
+
+// readCPUGovernor reads the governors and scaling driver under sysRoot
+func readCPUGovernor(sysRoot string) CPUGovernorInfo {
+	var info CPUGovernorInfo
+	seen := make(map[string]bool)
+
+	cpus, _ := filepath.Glob(filepath.Join(sysRoot, "devices", "system", "cpu", "cpu[0-9]*"))
+	for _, cpu := range cpus {
+		if data, err := os.ReadFile(filepath.Join(cpu, "cpufreq", "scaling_governor")); err == nil {
+			if governor := strings.TrimSpace(string(data)); governor != "" && !seen[governor] {
+				seen[governor] = true
+				info.Governors = append(info.Governors, governor)
+			}
+		}
+		if info.Driver == "" {
+			if data, err := os.ReadFile(filepath.Join(cpu, "cpufreq", "scaling_driver")); err == nil {
+				info.Driver = strings.TrimSpace(string(data))
+			}
+		}
+	}
+	sort.Strings(info.Governors)
+	return info
+}
+
+// governorWarning explains how the governor will bias results, or returns ""
+// when it won't. intel_pstate and amd-pstate-epp name their default dynamic
+// policy "powersave", so only other drivers are pinned to low clocks by it.
+func governorWarning(info CPUGovernorInfo) string {
+	for _, governor := range info.Governors {
+		if governor != "powersave" {
+			continue
+		}
+		if info.Driver == "intel_pstate" || info.Driver == "amd-pstate-epp" {
+			return ""
+		}
+		return fmt.Sprintf("CPU governor is powersave (%s) - cores are held at low clocks, so speeds understate this device; switch to schedutil or performance before benchmarking",
+			info.Driver)
+	}
+	return ""
+}
+
+// generateThermalInsights reports the peak temperature and clock drops; the
+// throttling count itself is reported with the other performance insights
+func generateThermalInsights(results []BenchmarkResult) []string {
+	var insights []string
+	var measured, dropped int
+	var hottest float64
+	slowest := 100.0
+	for _, result := range results {
+		if result.Thermal == nil {
+			continue
+		}
+		measured++
+		if result.Thermal.MaxTempC > hottest {
+			hottest = result.Thermal.MaxTempC
+		}
+		if result.Thermal.FreqDrops > 0 {
+			dropped++
+			if result.Thermal.MinFreqPercent < slowest {
+				slowest = result.Thermal.MinFreqPercent
+			}
+		}
+	}
+
+	if hottest > 0 {
+		insights = append(insights, fmt.Sprintf("Peak temperature: %.0f°C", hottest))
+	}
+	if dropped > 0 {
+		insights = append(insights, fmt.Sprintf("CPU clocked down during %d/%d tests (to %.0f%% of max)", dropped, measured, slowest))
+	}
+	return insights
+}
+
+// formatThermalReading summarizes a run for progress output
+func formatThermalReading(reading *ThermalReading) string {
+	var parts []string
+	if reading.Sensors {
+		parts = append(parts, fmt.Sprintf("%.0f→%.0f°C", reading.StartTempC, reading.MaxTempC))
+	}
+	if reading.FreqSamples > 0 {
+		parts = append(parts, fmt.Sprintf("clock ≥%.0f%% of max", reading.MinFreqPercent))
+	}
+	if reading.ThrottleEvents > 0 {
+		parts = append(parts, fmt.Sprintf("%d throttle events", reading.ThrottleEvents))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// readSysInt reads a sysfs file holding one integer
+func readSysInt(path string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	return value, err == nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureSysfs is a sysfs tree under testdata/sysfs
+func fixtureSysfs(name string) string {
+	return "testdata/sysfs/" + name
+}
+
+func TestReadThermalSnapshot(t *testing.T) {
+	tests := []struct {
+		fixture       string
+		maxTempC      float64
+		atTrip        bool
+		freqPercent   float64
+		counters      bool
+		throttleCount int64
+	}{
+		// zone1 reports 0, as disabled zones do, and is skipped
+		{"thermal-idle", 45, false, 100, true, 2},
+		// cpu1 has no cpuinfo_max_freq and is scaled by scaling_max_freq
+		{"thermal-throttled", 95, true, 50, true, 8},
+		{"thermal-powersave", 72, true, 60, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			snapshot, ok := readThermalSnapshot(fixtureSysfs(tt.fixture))
+			if !ok || !snapshot.sensors || !snapshot.freqScaling {
+				t.Fatalf("snapshot = %+v, %v", snapshot, ok)
+			}
+			if snapshot.maxTempC != tt.maxTempC || snapshot.atTrip != tt.atTrip || snapshot.freqPercent != tt.freqPercent {
+				t.Errorf("snapshot = %+v", snapshot)
+			}
+			if snapshot.counters != tt.counters || snapshot.throttleCount != tt.throttleCount {
+				t.Errorf("throttle counters = %v %d, want %v %d", snapshot.counters, snapshot.throttleCount, tt.counters, tt.throttleCount)
+			}
+		})
+	}
+
+	if _, ok := readThermalSnapshot(t.TempDir()); ok {
+		t.Error("empty sysfs should report no sensors")
+	}
+}
+
+// replayThermal serves readings from the fixtures in turn, repeating the last
+func replayThermal(t *testing.T, fixtures ...string) {
+	t.Helper()
+	var snapshots []thermalSnapshot
+	for _, fixture := range fixtures {
+		snapshot, ok := readThermalSnapshot(fixtureSysfs(fixture))
+		if !ok {
+			t.Fatalf("fixture %s is unreadable", fixture)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	original := thermalProbe
+	t.Cleanup(func() { thermalProbe = original })
+	thermalProbe = func() (thermalSnapshot, bool) {
+		snapshot := snapshots[0]
+		if len(snapshots) > 1 {
+			snapshots = snapshots[1:]
+		}
+		return snapshot, true
+	}
+}
+
+func TestSampleThermalDetectsThrottling(t *testing.T) {
+	// Idle before the request, hot and clocked down while it runs
+	replayThermal(t, "thermal-idle", "thermal-throttled")
+
+	finish := sampleThermal()
+	time.Sleep(thermalSampleInterval + thermalSampleInterval/4)
+	reading := finish()
+
+	if reading == nil {
+		t.Fatal("no reading")
+	}
+	if reading.StartTempC != 45 || reading.MaxTempC != 95 {
+		t.Errorf("temperatures = %v→%v", reading.StartTempC, reading.MaxTempC)
+	}
+	if reading.FreqSamples != 1 || reading.FreqDrops != 1 || reading.MinFreqPercent != 50 {
+		t.Errorf("frequency = %+v", reading)
+	}
+	// The kernel's counters went from 2 to 8
+	if reading.ThrottleEvents != 6 {
+		t.Errorf("throttle events = %d", reading.ThrottleEvents)
+	}
+}
+
+func TestSampleThermalTripPointWithoutCounters(t *testing.T) {
+	replayThermal(t, "thermal-powersave")
+
+	finish := sampleThermal()
+	time.Sleep(thermalSampleInterval + thermalSampleInterval/4)
+	reading := finish()
+
+	// One sample during the run, clocked down at the passive trip point
+	if reading == nil || reading.FreqDrops != 1 || reading.ThrottleEvents != 1 {
+		t.Errorf("reading = %+v", reading)
+	}
+}
+
+func TestGovernorWarning(t *testing.T) {
+	tests := []struct {
+		fixture string
+		info    CPUGovernorInfo
+		warns   bool
+	}{
+		{"thermal-idle", CPUGovernorInfo{Governors: []string{"schedutil"}, Driver: "acpi-cpufreq"}, false},
+		{"thermal-powersave", CPUGovernorInfo{Governors: []string{"powersave"}, Driver: "cpufreq-dt"}, true},
+		{"governor-intel-pstate", CPUGovernorInfo{Governors: []string{"powersave"}, Driver: "intel_pstate"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			info := readCPUGovernor(fixtureSysfs(tt.fixture))
+			if strings.Join(info.Governors, ",") != strings.Join(tt.info.Governors, ",") || info.Driver != tt.info.Driver {
+				t.Errorf("governor = %+v, want %+v", info, tt.info)
+			}
+			warning := governorWarning(info)
+			if (warning != "") != tt.warns {
+				t.Errorf("warning = %q", warning)
+			}
+			if tt.warns && !strings.Contains(warning, "powersave (cpufreq-dt)") {
+				t.Errorf("warning = %q", warning)
+			}
+		})
+	}
+}
@@ -6,63 +6,104 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 )
 
 // ModelResult represents the performance and output of a single model test
 type ModelResult struct {
-	Model          string        `json:"model"`
-	Prompt         string        `json:"prompt"`
-	Output         string        `json:"output"`
-	ResponseTime   time.Duration `json:"response_time"`
-	Success        bool          `json:"success"`
-	Error          string        `json:"error,omitempty"`
-	OutputLength   int           `json:"output_length"`
-	Timestamp      time.Time     `json:"timestamp"`
+	Model           string            `json:"model"`
+	Backend         string            `json:"backend"`
+	Phase           string            `json:"phase"` // "cold" (model just loaded) or "warm"
+	Prompt          string            `json:"prompt"`
+	Options         GenerationOptions `json:"options"` // effective options sent to the backend
+	Output          string            `json:"output"`
+	ResponseTime    time.Duration     `json:"response_time"`
+	Success         bool              `json:"success"`
+	Error           string            `json:"error,omitempty"`
+	OutputLength    int               `json:"output_length"`
+	TokensPerSecond float64           `json:"tokens_per_second"` // measured by the backend, not estimated
+	Timestamp       time.Time         `json:"timestamp"`
+
+	// Streaming measurements
+	TimeToFirstToken  time.Duration      `json:"time_to_first_token"`
+	InterTokenLatency LatencyPercentiles `json:"inter_token_latency"`
+	DecodeTimeline    []DecodeSample     `json:"decode_timeline,omitempty"`
+
+	// Conversation measurements, for test cases with follow-up turns
+	Turns         []TurnResult `json:"turns,omitempty"`
+	FactRetention float64      `json:"fact_retention"` // share of expected facts kept across turns; set only with Turns
+
+	// Structured-output and tool-calling test cases only
+	Structured *StructuredResult `json:"structured,omitempty"`
+	ToolCalls  *ToolCallResult   `json:"tool_calls,omitempty"`
+
+	GenerationMetrics
 }
 
 // TestCase represents a scenario to test across models
 type TestCase struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Prompt      string `json:"prompt"`
-	UseCase     string `json:"use_case"` // "devlog", "blog", "social", "capture"
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Prompt      string                `json:"prompt"`
+	UseCase     string                `json:"use_case"`             // "devlog", "blog", "social", "capture"
+	Options     GenerationOptions     `json:"options,omitempty"`    // overrides ExperimentConfig.Options
+	Turns       []ConversationTurn    `json:"turns,omitempty"`      // follow-ups that make Prompt the start of a chat
+	Structured  *StructuredOutputSpec `json:"structured,omitempty"` // ask for JSON matching a schema; Turns are ignored
+	ToolCalls   *ToolCallSpec         `json:"tool_calls,omitempty"` // offer stubbed tools and score the calls; Turns are ignored
 }
 
 // ExperimentConfig holds the experiment configuration
 type ExperimentConfig struct {
-	Models    []string   `json:"models"`
-	TestCases []TestCase `json:"test_cases"`
-	TimeoutSec int       `json:"timeout_sec"`
-	Runs      int        `json:"runs"` // Number of times to run each test
+	Models     []string                 `json:"models"`
+	TestCases  []TestCase               `json:"test_cases"`
+	TimeoutSec int                      `json:"timeout_sec"`
+	Runs       int                      `json:"runs"`               // Number of times to run each test
+	Backends   map[string]BackendConfig `json:"backends,omitempty"` // model -> runtime, Ollama if absent
+	Options    GenerationOptions        `json:"options,omitempty"`  // defaults for every test case
 }
 
 // ExperimentResults holds all results from the experiment
 type ExperimentResults struct {
 	Config      ExperimentConfig `json:"config"`
-	Results     []ModelResult    `json:"results"`
+	Results     []ModelResult    `json:"results"`         // warm runs only
+	ColdStarts  []ModelResult    `json:"cold_starts"`     // one per model, run right after unloading it
+	Pulls       []PullResult     `json:"pulls,omitempty"` // models downloaded by --pull-missing
 	Summary     ResultSummary    `json:"summary"`
 	GeneratedAt time.Time        `json:"generated_at"`
 }
 
 // ResultSummary provides aggregate statistics
 type ResultSummary struct {
-	ModelStats map[string]ModelStats `json:"model_stats"`
-	BestModel  map[string]string     `json:"best_model"` // use_case -> model
+	ModelStats         map[string]ModelStats `json:"model_stats"`
+	BestModel          map[string]string     `json:"best_model"` // use_case -> model
+	StructuredRankings []StructuredRanking   `json:"structured_rankings,omitempty"`
+	ToolRankings       []ToolRanking         `json:"tool_rankings,omitempty"`
 }
 
 // ModelStats contains aggregate statistics for a model
 type ModelStats struct {
-	SuccessRate     float64       `json:"success_rate"`
-	AvgResponseTime time.Duration `json:"avg_response_time"`
-	AvgOutputLength float64       `json:"avg_output_length"`
-	TotalTests      int           `json:"total_tests"`
+	SuccessRate         float64        `json:"success_rate"`
+	AvgResponseTime     time.Duration  `json:"avg_response_time"`
+	AvgTimeToFirstToken time.Duration  `json:"avg_time_to_first_token"`
+	AvgOutputLength     float64        `json:"avg_output_length"`
+	AvgTokensPerSecond  float64        `json:"avg_tokens_per_second"`
+	TotalTests          int            `json:"total_tests"`
+	Startup             StartupProfile `json:"startup"`
+
+	// Multi-turn test cases only
+	Conversations    int             `json:"conversations"`
+	AvgFactRetention float64         `json:"avg_fact_retention"`
+	TurnLatency      []time.Duration `json:"turn_latency,omitempty"` // average response time of each turn
 }
 
 func main() {
-	if len(os.Args) > 1 && os.Args[1] == "--help" {
+	opts, args, err := parseBackendOptions(os.Args[1:])
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	
+	if len(args) > 0 && args[0] == "--help" {
 		printHelp()
 		return
 	}
@@ -73,8 +114,8 @@ func main() {
 	config := getDefaultConfig()
 	
 	// Allow custom config file
-	if len(os.Args) > 1 {
-		configPath := os.Args[1]
+	if len(args) > 0 {
+		configPath := args[0]
 		if customConfig, err := loadConfig(configPath); err == nil {
 			config = customConfig
 			fmt.Printf("📋 Loaded custom config from %s\n", configPath)
@@ -86,10 +127,32 @@ func main() {
 	fmt.Printf("🎯 Testing %d models across %d test cases\n", len(config.Models), len(config.TestCases))
 	fmt.Printf("⏱️  Timeout: %d seconds per test\n", config.TimeoutSec)
 	fmt.Printf("🔄 Runs per test: %d\n", config.Runs)
+	fmt.Printf("⚙️  Generation options: %s\n", config.Options)
+	
+	router, err := newRoutedBackend(config.Backends)
+	if err != nil {
+		log.Fatalf("❌ Invalid backend configuration: %v", err)
+	}
+	backend, finish, err := opts.apply(router)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
 	
 	// Verify models are available
 	fmt.Println("\n🔍 Checking model availability...")
-	availableModels := checkModelAvailability(config.Models)
+	availableModels := checkModelAvailability(backend, config.Models)
+	var pulls []PullResult
+	if opts.PullMissing && !opts.offline() {
+		if missing := missingModels(config.Models, availableModels); len(missing) > 0 {
+			fmt.Printf("\n📥 Pulling %d missing models...\n", len(missing))
+			pulls = pullMissingModels(backend, missing)
+			availableModels = append(availableModels, pulledModels(pulls)...)
+		}
+	}
+	if len(availableModels) == 0 && opts.offline() {
+		// Offline runs fall back to whatever the script or cassette serves
+		availableModels = withoutEmbeddingModels(listBackendModels(backend), modelInventory(backend))
+	}
 	if len(availableModels) == 0 {
 		log.Fatal("❌ No models available. Please install models with: ollama pull <model-name>")
 	}
@@ -97,15 +160,22 @@ func main() {
 
 	// Run experiments
 	fmt.Println("\n🚀 Starting experiments...")
-	results := runExperiments(config, availableModels)
+	results, coldStarts := runExperiments(backend, config, availableModels)
+	if err := finish(); err != nil {
+		log.Printf("⚠️  Failed to save cassette: %v", err)
+	} else if opts.Record != "" {
+		fmt.Printf("📼 Cassette saved to: %s\n", opts.Record)
+	}
 	
 	// Generate summary
-	summary := generateSummary(results)
+	summary := generateSummary(results, coldStarts)
 	
 	// Save results
 	experimentResults := ExperimentResults{
 		Config:      config,
 		Results:     results,
+		ColdStarts:  coldStarts,
+		Pulls:       pulls,
 		Summary:     summary,
 		GeneratedAt: time.Now(),
 	}
@@ -176,41 +246,47 @@ func loadConfig(path string) (ExperimentConfig, error) {
 	return config, json.Unmarshal(data, &config)
 }
 
-func checkModelAvailability(models []string) []string {
+func checkModelAvailability(backend Backend, models []string) []string {
 	var available []string
+	inventory := modelInventory(backend)
 	for _, model := range models {
-		if isModelAvailable(model) {
+		if isModelAvailable(backend, model) {
 			available = append(available, model)
-			fmt.Printf("  ✅ %s\n", model)
+			if info, ok := inventory.Lookup(model); ok {
+				fmt.Printf("  ✅ %s (%s, %s, %dMB)\n", model, info.ParameterSize, info.QuantizationLevel, info.SizeMB())
+			} else {
+				fmt.Printf("  ✅ %s\n", model)
+			}
 		} else {
-			fmt.Printf("  ❌ %s (not installed)\n", model)
+			fmt.Printf("  ❌ %s (not installed; --pull-missing downloads it)\n", model)
 		}
 	}
 	return available
 }
 
-func isModelAvailable(model string) bool {
+func isModelAvailable(backend Backend, model string) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	
-	cmd := exec.CommandContext(ctx, "ollama", "list")
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-	
-	return strings.Contains(string(output), model)
+	return modelAvailable(ctx, backend, model)
 }
 
-func runExperiments(config ExperimentConfig, models []string) []ModelResult {
-	var results []ModelResult
+// runExperiments returns the warm results and, where the backend can unload
+// models, one cold-start result per model
+func runExperiments(backend Backend, config ExperimentConfig, models []string) ([]ModelResult, []ModelResult) {
+	var results, coldStarts []ModelResult
 	total := len(models) * len(config.TestCases) * config.Runs
 	current := 0
 	
 	for _, model := range models {
 		fmt.Printf("\n🤖 Testing model: %s\n", model)
 		
+		if cold, ok := runColdStart(backend, model, config); ok {
+			coldStarts = append(coldStarts, cold)
+		}
+		
 		for _, testCase := range config.TestCases {
+			testCase.Options = testCase.Options.withDefaults(config.Options)
 			fmt.Printf("  📝 %s", testCase.Name)
 			
 			for run := 0; run < config.Runs; run++ {
@@ -219,7 +295,7 @@ func runExperiments(config ExperimentConfig, models []string) []ModelResult {
 					fmt.Printf(" (run %d/%d)", run+1, config.Runs)
 				}
 				
-				result := testModel(model, testCase, config.TimeoutSec)
+				result := testModel(backend, model, testCase, config.TimeoutSec)
 				results = append(results, result)
 				
 				// Progress indicator
@@ -227,7 +303,17 @@ func runExperiments(config ExperimentConfig, models []string) []ModelResult {
 				fmt.Printf(" [%.1f%%]", progress)
 				
 				if result.Success {
-					fmt.Printf(" ✅ %v", result.ResponseTime.Round(time.Millisecond))
+					fmt.Printf(" ✅ %v (TTFT %v, %.1f t/s)", result.ResponseTime.Round(time.Millisecond),
+						result.TimeToFirstToken.Round(time.Millisecond), result.TokensPerSecond)
+					if len(result.Turns) > 0 {
+						fmt.Printf(" %d turns, facts kept %.0f%%", len(result.Turns), result.FactRetention*100)
+					}
+					if result.Structured != nil {
+						fmt.Printf(" %s", formatStructuredResult(*result.Structured))
+					}
+					if result.ToolCalls != nil {
+						fmt.Printf(" %s", formatToolCallResult(*result.ToolCalls))
+					}
 				} else {
 					fmt.Printf(" ❌ %s", result.Error)
 				}
@@ -236,26 +322,91 @@ func runExperiments(config ExperimentConfig, models []string) []ModelResult {
 		}
 	}
 	
-	return results
+	return results, coldStarts
 }
 
-func testModel(model string, testCase TestCase, timeoutSec int) ModelResult {
+// runColdStart unloads the model and times the first single-prompt test case
+// against it, so load time is measured once instead of skewing the warm averages
+func runColdStart(backend Backend, model string, config ExperimentConfig) (ModelResult, bool) {
+	testCase, ok := coldStartCase(config.TestCases)
+	if !ok {
+		fmt.Println("  🧊 Cold start: skipped (no single-prompt test case)")
+		return ModelResult{}, false
+	}
+	
+	supported, err := unloadModel(backend, model)
+	if !supported {
+		fmt.Println("  🧊 Cold start: skipped (backend can't unload models)")
+		return ModelResult{}, false
+	}
+	if err != nil {
+		fmt.Printf("  🧊 Cold start: skipped (unload failed: %v)\n", err)
+		return ModelResult{}, false
+	}
+	
+	testCase.Options = testCase.Options.withDefaults(config.Options)
+	result := testModel(backend, model, testCase, config.TimeoutSec)
+	result.Phase = "cold"
+	
+	if result.Success {
+		fmt.Printf("  🧊 Cold start: %v (load %v)\n",
+			result.ResponseTime.Round(time.Millisecond), result.LoadDuration.Round(time.Millisecond))
+	} else {
+		fmt.Printf("  🧊 Cold start: ❌ %s\n", result.Error)
+	}
+	return result, true
+}
+
+// coldStartCase picks the first plain prompt: chats, structured output and
+// tool calls make several requests, so the cold latency would cover more
+// than the load and one answer
+func coldStartCase(testCases []TestCase) (TestCase, bool) {
+	for _, testCase := range testCases {
+		if len(testCase.Turns) == 0 && testCase.Structured == nil && testCase.ToolCalls == nil {
+			return testCase, true
+		}
+	}
+	return TestCase{}, false
+}
+
+func testModel(backend Backend, model string, testCase TestCase, timeoutSec int) ModelResult {
 	start := time.Now()
 	
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
 	defer cancel()
 	
-	cmd := exec.CommandContext(ctx, "ollama", "run", model)
-	cmd.Stdin = strings.NewReader(testCase.Prompt)
-	
-	output, err := cmd.Output()
+	var resp GenerateResponse
+	var turns []TurnResult
+	var structured *StructuredResult
+	var toolCalls *ToolCallResult
+	var err error
+	switch {
+	case testCase.Structured != nil:
+		var outcome StructuredResult
+		outcome, resp, err = runStructuredTest(ctx, backend, model, testCase.Prompt, *testCase.Structured, testCase.Options)
+		structured = &outcome
+	case testCase.ToolCalls != nil:
+		var outcome ToolCallResult
+		outcome, resp, err = runToolCallTest(ctx, backend, model, testCase.Prompt, *testCase.ToolCalls, testCase.Options)
+		toolCalls = &outcome
+	case len(testCase.Turns) > 0:
+		turns, resp, err = runConversation(ctx, backend, model, testCase.Prompt, testCase.Turns, testCase.Options, nil)
+	default:
+		resp, err = backend.Generate(ctx, GenerateRequest{Model: model, Prompt: testCase.Prompt, Options: testCase.Options})
+	}
 	responseTime := time.Since(start)
 	
 	result := ModelResult{
 		Model:        model,
+		Backend:      resp.Backend,
+		Phase:        "warm",
 		Prompt:       testCase.Prompt,
+		Options:      testCase.Options,
 		ResponseTime: responseTime,
 		Timestamp:    start,
+		Turns:        turns,
+		Structured:   structured,
+		ToolCalls:    toolCalls,
 	}
 	
 	if err != nil {
@@ -264,14 +415,23 @@ func testModel(model string, testCase TestCase, timeoutSec int) ModelResult {
 		return result
 	}
 	
+	// A conversation is judged by its final reply
 	result.Success = true
-	result.Output = strings.TrimSpace(string(output))
+	result.Output = resp.Output
 	result.OutputLength = len(result.Output)
+	if len(turns) > 0 {
+		result.FactRetention = factRetention(turns)
+	}
+	result.GenerationMetrics = resp.Metrics
+	result.TokensPerSecond = resp.Metrics.DecodeRate()
+	result.TimeToFirstToken = resp.Timing.TimeToFirstToken
+	result.InterTokenLatency = resp.Timing.InterTokenLatency
+	result.DecodeTimeline = resp.Timing.DecodeTimeline
 	
 	return result
 }
 
-func generateSummary(results []ModelResult) ResultSummary {
+func generateSummary(results []ModelResult, coldStarts []ModelResult) ResultSummary {
 	summary := ResultSummary{
 		ModelStats: make(map[string]ModelStats),
 		BestModel:  make(map[string]string),
@@ -285,9 +445,19 @@ func generateSummary(results []ModelResult) ResultSummary {
 	
 	for model, modelResults := range modelData {
 		stats := calculateModelStats(modelResults)
+		stats.Startup.WarmLatency = stats.AvgResponseTime
 		summary.ModelStats[model] = stats
 	}
 	
+	for _, cold := range coldStarts {
+		stats := summary.ModelStats[cold.Model]
+		if cold.Success {
+			stats.Startup.LoadTime = cold.LoadDuration
+			stats.Startup.ColdLatency = cold.ResponseTime
+		}
+		summary.ModelStats[cold.Model] = stats
+	}
+	
 	// Find best models per use case (by success rate, then by speed)
 	useCaseResults := make(map[string][]ModelResult)
 	for _, result := range results {
@@ -303,6 +473,19 @@ func generateSummary(results []ModelResult) ResultSummary {
 		}
 	}
 	
+	structured := make(map[string][]StructuredResult)
+	toolCalls := make(map[string][]ToolCallResult)
+	for _, result := range results {
+		if result.Structured != nil {
+			structured[result.Model] = append(structured[result.Model], *result.Structured)
+		}
+		if result.ToolCalls != nil {
+			toolCalls[result.Model] = append(toolCalls[result.Model], *result.ToolCalls)
+		}
+	}
+	summary.StructuredRankings = rankStructuredOutput(structured)
+	summary.ToolRankings = rankToolCalling(toolCalls)
+	
 	return summary
 }
 
@@ -313,13 +496,25 @@ func calculateModelStats(results []ModelResult) ModelStats {
 	
 	successCount := 0
 	var totalResponseTime time.Duration
+	var totalTimeToFirstToken time.Duration
 	var totalOutputLength int
+	var totalTokensPerSecond float64
+	var totalFactRetention float64
+	var conversations [][]TurnResult
 	
 	for _, result := range results {
+		if len(result.Turns) > 0 {
+			conversations = append(conversations, result.Turns)
+		}
 		if result.Success {
 			successCount++
 			totalResponseTime += result.ResponseTime
+			totalTimeToFirstToken += result.TimeToFirstToken
 			totalOutputLength += result.OutputLength
+			totalTokensPerSecond += result.TokensPerSecond
+			if len(result.Turns) > 0 {
+				totalFactRetention += result.FactRetention
+			}
 		}
 	}
 	
@@ -330,7 +525,16 @@ func calculateModelStats(results []ModelResult) ModelStats {
 	
 	if successCount > 0 {
 		stats.AvgResponseTime = totalResponseTime / time.Duration(successCount)
+		stats.AvgTimeToFirstToken = totalTimeToFirstToken / time.Duration(successCount)
 		stats.AvgOutputLength = float64(totalOutputLength) / float64(successCount)
+		stats.AvgTokensPerSecond = totalTokensPerSecond / float64(successCount)
+	}
+	
+	// Failed conversations count against retention: the facts were not kept
+	if len(conversations) > 0 {
+		stats.Conversations = len(conversations)
+		stats.AvgFactRetention = totalFactRetention / float64(len(conversations))
+		stats.TurnLatency = averageTurnLatency(conversations)
 	}
 	
 	return stats
@@ -425,10 +629,25 @@ func printSummary(summary ResultSummary) {
 			stats.SuccessRate*100, int(stats.SuccessRate*float64(stats.TotalTests)), stats.TotalTests)
 		if stats.SuccessRate > 0 {
 			fmt.Printf("    Avg Response Time: %v\n", stats.AvgResponseTime.Round(time.Millisecond))
+			fmt.Printf("    Avg Time to First Token: %v\n", stats.AvgTimeToFirstToken.Round(time.Millisecond))
 			fmt.Printf("    Avg Output Length: %.0f chars\n", stats.AvgOutputLength)
+			fmt.Printf("    Avg Decode Speed: %.1f tokens/s\n", stats.AvgTokensPerSecond)
+		}
+		if stats.Startup.ColdLatency > 0 {
+			fmt.Printf("    Load Time: %v, Cold: %v, Warm: %v\n",
+				stats.Startup.LoadTime.Round(time.Millisecond),
+				stats.Startup.ColdLatency.Round(time.Millisecond),
+				stats.Startup.WarmLatency.Round(time.Millisecond))
+		}
+		if stats.Conversations > 0 {
+			fmt.Printf("    Conversations: %d, facts kept %.0f%%, per-turn latency %s\n",
+				stats.Conversations, stats.AvgFactRetention*100, formatTurnLatency(stats.TurnLatency))
 		}
 	}
 	
+	printStructuredRankings(summary.StructuredRankings)
+	printToolRankings(summary.ToolRankings)
+	
 	fmt.Println("\n💡 Recommendations:")
 	fastestModel := findFastestModel(summary.ModelStats)
 	mostReliableModel := findMostReliableModel(summary.ModelStats)
@@ -472,24 +691,33 @@ func printHelp() {
 	fmt.Println("================================")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  go run model_comparison.go [config.json]")
+	fmt.Println("  go run model_comparison.go bench_*.go [options] [config.json]")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  config.json    Optional JSON config file (uses defaults if not provided)")
 	fmt.Println("  --help         Show this help message")
+	fmt.Println(backendOptionsHelp)
 	fmt.Println()
 	fmt.Println("Examples:")
-	fmt.Println("  go run model_comparison.go")
-	fmt.Println("  go run model_comparison.go custom_config.json")
+	fmt.Println("  go run model_comparison.go bench_*.go")
+	fmt.Println("  go run model_comparison.go bench_*.go custom_config.json")
+	fmt.Println("  go run model_comparison.go bench_*.go --record=results/cassette.json")
+	fmt.Println("  go run model_comparison.go bench_*.go --replay=results/cassette.json")
+	fmt.Println("  go run model_comparison.go bench_*.go --pull-missing")
 	fmt.Println()
 	fmt.Println("The tool will:")
 	fmt.Println("  1. Check which models are available via Ollama")
 	fmt.Println("  2. Run test prompts across all available models")
-	fmt.Println("  3. Measure response times and success rates")
+	fmt.Println("  3. Measure response times, token throughput and success rates")
 	fmt.Println("  4. Generate recommendations for different use cases")
 	fmt.Println("  5. Save detailed results to JSON file")
 	fmt.Println()
-	fmt.Println("Make sure you have Ollama installed and models pulled:")
+	fmt.Println("Generations go through the Ollama REST API at $OLLAMA_HOST (default localhost:11434).")
+	fmt.Println("To serve a model from llama.cpp, LM Studio or vLLM instead, add it to \"backends\":")
+	fmt.Println(`  "backends": {"llama3-llamacpp": {"type": "openai", "base_url": "http://localhost:8080",`)
+	fmt.Println(`               "api_key_env": "LLAMACPP_API_KEY", "model_id": "llama-3-8b-instruct"}}`)
+	fmt.Println()
+	fmt.Println("Make sure you have Ollama installed and models pulled (or pass --pull-missing):")
 	fmt.Println("  ollama pull mistral:latest")
 	fmt.Println("  ollama pull llama2:7b")
 	fmt.Println("  ollama pull codellama:7b")
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestColdStartCase(t *testing.T) {
+	chat := TestCase{Name: "chat", Prompt: "hi", Turns: []ConversationTurn{{User: "and then?"}}}
+	structured := TestCase{Name: "json", Prompt: "list", Structured: &StructuredOutputSpec{}}
+	tools := TestCase{Name: "tools", Prompt: "weather", ToolCalls: &ToolCallSpec{}}
+	plain := TestCase{Name: "devlog", Prompt: "summarize"}
+
+	if testCase, ok := coldStartCase([]TestCase{chat, structured, tools, plain}); !ok || testCase.Name != "devlog" {
+		t.Errorf("picked %+v, %v", testCase, ok)
+	}
+	if _, ok := coldStartCase([]TestCase{chat, structured, tools}); ok {
+		t.Error("picked a case that makes several requests")
+	}
+}
+
+func TestRunColdStartSkipsWithoutPlainCase(t *testing.T) {
+	backend := &MockBackend{Script: defaultMockScript()}
+	config := ExperimentConfig{TimeoutSec: 5, TestCases: []TestCase{
+		{Name: "chat", Prompt: "hi", Turns: []ConversationTurn{{User: "and then?"}}},
+	}}
+	if _, ok := runColdStart(backend, "mock-fast:3b", config); ok {
+		t.Error("cold start ran a multi-turn case")
+	}
+
+	config.TestCases = append(config.TestCases, TestCase{Name: "devlog", Prompt: "Summarize today's work"})
+	backend.Generate(context.Background(), GenerateRequest{Model: "mock-fast:3b", Prompt: "warm up"})
+	cold, ok := runColdStart(backend, "mock-fast:3b", config)
+	if !ok || cold.Phase != "cold" || cold.Prompt != "Summarize today's work" {
+		t.Fatalf("cold = %+v, %v", cold, ok)
+	}
+	// The model was unloaded first, so the run paid the load
+	if cold.LoadDuration == 0 {
+		t.Errorf("load = %v", cold.LoadDuration)
+	}
+}
@@ -159,13 +159,13 @@ show_usage() {
     echo "====================="
     echo
     echo "1. Run basic experiment:"
-    echo "   go run model_comparison.go"
+    echo "   go run model_comparison.go bench_*.go"
     echo
     echo "2. Use custom configuration:"
-    echo "   go run model_comparison.go sample_config.json"
+    echo "   go run model_comparison.go bench_*.go sample_config.json"
     echo
     echo "3. Quick test with just installed models:"
-    echo "   go run model_comparison.go --quick"
+    echo "   go run model_comparison.go bench_*.go --quick"
     echo
     echo "4. View results:"
     echo "   ls -la results/"
@@ -223,4 +223,4 @@ main "$@"
 
 echo
 echo -e "${GREEN}🎉 Setup complete!${NC}"
-echo -e "Ready to run: ${BLUE}go run model_comparison.go${NC}"
\ No newline at end of file
+echo -e "Ready to run: ${BLUE}go run model_comparison.go bench_*.go${NC}"
\ No newline at end of file
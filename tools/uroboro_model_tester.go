@@ -6,35 +6,56 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 )
 
 // UroboroTestResult represents test results for uroboro-specific scenarios
 type UroboroTestResult struct {
-	Model             string        `json:"model"`
-	UseCase           string        `json:"use_case"`
-	TestName          string        `json:"test_name"`
-	Input             string        `json:"input"`
-	Output            string        `json:"output"`
-	ResponseTime      time.Duration `json:"response_time"`
-	Success           bool          `json:"success"`
-	Error             string        `json:"error,omitempty"`
-	QualityScore      int           `json:"quality_score"`      // 1-5 rating
-	FormatCompliance  bool          `json:"format_compliance"`  // Does it follow markdown/format rules?
-	TechnicalAccuracy bool          `json:"technical_accuracy"` // Are technical terms correct?
-	Timestamp         time.Time     `json:"timestamp"`
+	Model             string            `json:"model"`
+	Backend           string            `json:"backend"`
+	UseCase           string            `json:"use_case"`
+	TestName          string            `json:"test_name"`
+	Input             string            `json:"input"`
+	Options           GenerationOptions `json:"options"` // effective options sent to the backend
+	Output            string            `json:"output"`
+	ResponseTime      time.Duration     `json:"response_time"`
+	TokensPerSecond   float64           `json:"tokens_per_second"` // measured by the backend
+	Success           bool              `json:"success"`
+	Error             string            `json:"error,omitempty"`
+	QualityScore      int               `json:"quality_score"`      // 1-5 rating
+	FormatCompliance  bool              `json:"format_compliance"`  // Does it follow markdown/format rules?
+	TechnicalAccuracy bool              `json:"technical_accuracy"` // Are technical terms correct?
+	Timestamp         time.Time         `json:"timestamp"`
+
+	// Streaming measurements
+	TimeToFirstToken  time.Duration      `json:"time_to_first_token"`
+	InterTokenLatency LatencyPercentiles `json:"inter_token_latency"`
+	DecodeTimeline    []DecodeSample     `json:"decode_timeline,omitempty"`
+
+	// Refinement conversations: Output and QualityScore describe the final reply
+	Turns         []TurnResult `json:"turns,omitempty"`
+	FactRetention float64      `json:"fact_retention"` // share of expected facts kept across turns; set only with Turns
+
+	// Machine-readable metadata and tool-calling cases: judged on their own
+	// track instead of prose quality
+	Structured *StructuredResult `json:"structured,omitempty"`
+	ToolCalls  *ToolCallResult   `json:"tool_calls,omitempty"`
+
+	GenerationMetrics
 }
 
 // UroboroTestCase represents a specific uroboro scenario
 type UroboroTestCase struct {
-	Name        string `json:"name"`
-	UseCase     string `json:"use_case"` // "capture", "devlog", "blog", "social"
-	Input       string `json:"input"`    // Simulated uroboro input
-	Prompt      string `json:"prompt"`   // Actual prompt sent to model
-	ExpectedLen int    `json:"expected_length_range"`
+	Name        string                `json:"name"`
+	UseCase     string                `json:"use_case"` // "capture", "devlog", "blog", "social"
+	Input       string                `json:"input"`    // Simulated uroboro input
+	Prompt      string                `json:"prompt"`   // Actual prompt sent to model
+	ExpectedLen int                   `json:"expected_length_range"`
+	Options     GenerationOptions     `json:"options,omitempty"`    // overrides ExperimentConfig.Options
+	Turns       []ConversationTurn    `json:"turns,omitempty"`      // refinement requests ("make it shorter") after Prompt
+	Structured  *StructuredOutputSpec `json:"structured,omitempty"` // JSON metadata matching a schema; Turns are ignored
+	ToolCalls   *ToolCallSpec         `json:"tool_calls,omitempty"` // stubbed tools (git log, file read); Turns are ignored
 }
 
 // UroboroExperiment holds the complete experiment configuration
@@ -42,23 +63,27 @@ type UroboroExperiment struct {
 	Models    []string           `json:"models"`
 	TestCases []UroboroTestCase  `json:"test_cases"`
 	Results   []UroboroTestResult `json:"results"`
+	Pulls     []PullResult       `json:"pulls,omitempty"` // models downloaded by --pull-missing
 	Summary   UroboroSummary     `json:"summary"`
 	Config    ExperimentConfig   `json:"config"`
 }
 
 // UroboroSummary provides uroboro-specific recommendations
 type UroboroSummary struct {
-	BestModelPerUseCase    map[string]string      `json:"best_model_per_use_case"`
-	PerformanceRecommendations map[string]string  `json:"performance_recommendations"`
-	QualityRankings        map[string][]ModelRanking `json:"quality_rankings"`
-	UroboroConfig          UroboroConfigRecommendation `json:"uroboro_config_recommendation"`
+	BestModelPerUseCase        map[string]string           `json:"best_model_per_use_case"`
+	PerformanceRecommendations map[string]string           `json:"performance_recommendations"`
+	QualityRankings            map[string][]ModelRanking   `json:"quality_rankings"`
+	StructuredRankings         []StructuredRanking         `json:"structured_rankings,omitempty"` // JSON metadata reliability
+	ToolRankings               []ToolRanking               `json:"tool_rankings,omitempty"`       // tool-calling reliability
+	UroboroConfig              UroboroConfigRecommendation `json:"uroboro_config_recommendation"`
 }
 
 // ModelRanking represents model ranking for a specific use case
 type ModelRanking struct {
-	Model    string  `json:"model"`
-	Score    float64 `json:"score"`
-	Reason   string  `json:"reason"`
+	Model               string        `json:"model"`
+	Score               float64       `json:"score"`
+	AvgTimeToFirstToken time.Duration `json:"avg_time_to_first_token"`
+	Reason              string        `json:"reason"`
 }
 
 // UroboroConfigRecommendation provides specific uroboro configuration advice
@@ -72,20 +97,47 @@ type UroboroConfigRecommendation struct {
 
 // ExperimentConfig holds experiment parameters
 type ExperimentConfig struct {
-	TimeoutSeconds int `json:"timeout_seconds"`
-	Runs          int `json:"runs"`
-	SkipSlow      bool `json:"skip_slow_models"`
+	TimeoutSeconds int                      `json:"timeout_seconds"`
+	Runs           int                      `json:"runs"`
+	SkipSlow       bool                     `json:"skip_slow_models"`
+	Backends       map[string]BackendConfig `json:"backends,omitempty"` // model -> runtime, Ollama if absent
+	Options        GenerationOptions        `json:"options,omitempty"`  // defaults for every test case
 }
 
 func main() {
+	opts, _, err := parseBackendOptions(os.Args[1:])
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	
 	fmt.Println("🐍 uroboro Model Performance Tester")
 	fmt.Println("===================================")
 	
 	// Initialize experiment
 	experiment := initializeExperiment()
 	
+	router, err := newRoutedBackend(experiment.Config.Backends)
+	if err != nil {
+		log.Fatalf("❌ Invalid backend configuration: %v", err)
+	}
+	backend, finish, err := opts.apply(router)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	
 	// Check available models
-	availableModels := checkAvailableModels(experiment.Models)
+	availableModels := checkAvailableModels(backend, experiment.Models)
+	if opts.PullMissing && !opts.offline() {
+		if missing := missingModels(experiment.Models, availableModels); len(missing) > 0 {
+			fmt.Printf("📥 Pulling %d missing models...\n", len(missing))
+			experiment.Pulls = pullMissingModels(backend, missing)
+			availableModels = append(availableModels, pulledModels(experiment.Pulls)...)
+		}
+	}
+	if len(availableModels) == 0 && opts.offline() {
+		// Offline runs fall back to whatever the script or cassette serves
+		availableModels = withoutEmbeddingModels(listBackendModels(backend), modelInventory(backend))
+	}
 	if len(availableModels) == 0 {
 		log.Fatal("❌ No models available. Run: ollama pull mistral:latest")
 	}
@@ -94,7 +146,12 @@ func main() {
 	
 	// Run experiments
 	fmt.Println("\n🧪 Running uroboro-specific tests...")
-	runUroboroExperiments(&experiment, availableModels)
+	runUroboroExperiments(backend, &experiment, availableModels)
+	if err := finish(); err != nil {
+		log.Printf("⚠️  Failed to save cassette: %v", err)
+	} else if opts.Record != "" {
+		fmt.Printf("📼 Cassette saved to: %s\n", opts.Record)
+	}
 	
 	// Analyze results and generate summary
 	experiment.Summary = generateUroboroSummary(experiment.Results)
@@ -165,6 +222,18 @@ func initializeExperiment() UroboroExperiment {
 			Prompt:  buildDevlogPrompt("Built RESTful API with Go and Gin. Added rate limiting, request validation, and comprehensive error handling. Integrated with PostgreSQL using GORM."),
 			ExpectedLen: 600,
 		},
+		{
+			Name:    "Devlog Refinement Conversation",
+			UseCase: "devlog",
+			Input:   "Cut checkout latency from 1.2s to 300ms by caching product prices in Redis and batching inventory lookups.",
+			Prompt:  buildDevlogPrompt("Cut checkout latency from 1.2s to 300ms by caching product prices in Redis and batching inventory lookups."),
+			ExpectedLen: 400,
+			Turns: []ConversationTurn{
+				{User: "Make it shorter: at most four bullet points.", Expect: []string{"Redis"}},
+				{User: "Add a section on what we should measure next.", Expect: []string{"300ms", "Redis"}},
+				{User: "Now condense the whole entry into a single sentence for the team channel.", Expect: []string{"300ms"}},
+			},
+		},
 		
 		// BLOG use cases (professional, external)
 		{
@@ -182,6 +251,51 @@ func initializeExperiment() UroboroExperiment {
 			ExpectedLen: 1000,
 		},
 		
+		// METADATA use cases (machine-readable capture metadata)
+		{
+			Name:    "Capture Metadata JSON",
+			UseCase: "metadata",
+			Input:   "Fixed race condition in the session cache that logged users out under load; added a mutex and a regression test",
+			Prompt:  buildMetadataPrompt("Fixed race condition in the session cache that logged users out under load; added a mutex and a regression test"),
+			ExpectedLen: 200,
+			Structured: &StructuredOutputSpec{
+				Schema:   captureMetadataSchema,
+				Expected: map[string]interface{}{"component": "session cache", "impact": "high", "type": "bugfix"},
+			},
+		},
+		
+		// TOOLS use cases (stubbed git log and file read; nothing is executed)
+		{
+			Name:    "Git History Lookup",
+			UseCase: "tools",
+			Input:   "What changed in internal/auth this week?",
+			Prompt:  "Summarize what changed in internal/auth over the last week for today's devlog.",
+			ExpectedLen: 300,
+			ToolCalls: &ToolCallSpec{
+				Tools:    uroboroTools,
+				Expected: []ToolCall{{Name: "git_log", Arguments: map[string]interface{}{"path": "internal/auth"}}},
+			},
+		},
+		{
+			Name:    "File Read Lookup",
+			UseCase: "tools",
+			Input:   "Capture the retry settings in config/retry.yaml",
+			Prompt:  "Write a short capture describing the retry settings in config/retry.yaml.",
+			ExpectedLen: 200,
+			ToolCalls: &ToolCallSpec{
+				Tools:    uroboroTools,
+				Expected: []ToolCall{{Name: "read_file", Arguments: map[string]interface{}{"path": "config/retry.yaml"}}},
+			},
+		},
+		{
+			Name:    "No Tool Needed",
+			UseCase: "tools",
+			Input:   "Fixed the login redirect loop",
+			Prompt:  "Summarize in one sentence: Fixed the login redirect loop caused by a stale session cookie.",
+			ExpectedLen: 100,
+			ToolCalls: &ToolCallSpec{Tools: uroboroTools},
+		},
+		
 		// SOCIAL use cases (engaging, concise)
 		{
 			Name:    "Achievement Social Post",
@@ -273,22 +387,56 @@ Requirements:
 Social Post:`, input)
 }
 
-func checkAvailableModels(models []string) []string {
+func buildMetadataPrompt(input string) string {
+	return fmt.Sprintf(`Extract capture metadata from this development insight for uroboro's index:
+
+Insight: %s
+
+Requirements:
+- tags: 2-5 short lowercase keywords
+- component: the part of the system that changed
+- impact: how much users or developers notice the change
+- type: the kind of change`, input)
+}
+
+// uroboroTools are the tools uroboro may get. They are never run: the stub
+// results stand in for a real repository.
+var uroboroTools = []ToolDefinition{
+	{
+		Name:        "git_log",
+		Description: "List recent commits, optionally limited to a path",
+		Parameters:  json.RawMessage(`{"type": "object", "required": ["path"], "properties": {"path": {"type": "string"}, "since": {"type": "string"}, "limit": {"type": "integer", "minimum": 1}}}`),
+		StubResult:  "a1b2c3d Rotate refresh tokens on use\n9f8e7d6 Add rate limit to login endpoint\n5c4b3a2 Fix session expiry off-by-one",
+	},
+	{
+		Name:        "read_file",
+		Description: "Read a file from the repository",
+		Parameters:  json.RawMessage(`{"type": "object", "required": ["path"], "properties": {"path": {"type": "string"}}}`),
+		StubResult:  "max_attempts: 5\nbackoff: exponential\ninitial_delay: 200ms\nmax_delay: 10s",
+	},
+}
+
+// captureMetadataSchema is the shape uroboro stores alongside a capture
+var captureMetadataSchema = json.RawMessage(`{
+  "type": "object",
+  "required": ["tags", "component", "impact", "type"],
+  "additionalProperties": false,
+  "properties": {
+    "tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z0-9-]+$"}, "minItems": 2, "maxItems": 5},
+    "component": {"type": "string", "minLength": 2},
+    "impact": {"type": "string", "enum": ["low", "medium", "high"]},
+    "type": {"type": "string", "enum": ["feature", "bugfix", "performance", "refactor", "docs"]}
+  }
+}`)
+
+func checkAvailableModels(backend Backend, models []string) []string {
 	var available []string
 	
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	
-	cmd := exec.CommandContext(ctx, "ollama", "list")
-	output, err := cmd.Output()
-	if err != nil {
-		fmt.Printf("⚠️  Error checking models: %v\n", err)
-		return available
-	}
-	
-	modelList := string(output)
 	for _, model := range models {
-		if strings.Contains(modelList, model) {
+		if modelAvailable(ctx, backend, model) {
 			available = append(available, model)
 		}
 	}
@@ -296,7 +444,7 @@ func checkAvailableModels(models []string) []string {
 	return available
 }
 
-func runUroboroExperiments(experiment *UroboroExperiment, models []string) {
+func runUroboroExperiments(backend Backend, experiment *UroboroExperiment, models []string) {
 	total := len(models) * len(experiment.TestCases) * experiment.Config.Runs
 	current := 0
 	
@@ -304,12 +452,13 @@ func runUroboroExperiments(experiment *UroboroExperiment, models []string) {
 		fmt.Printf("\n🤖 Testing model: %s\n", model)
 		
 		for _, testCase := range experiment.TestCases {
+			testCase.Options = testCase.Options.withDefaults(experiment.Config.Options)
 			fmt.Printf("  📝 %s (%s)", testCase.Name, testCase.UseCase)
 			
 			for run := 0; run < experiment.Config.Runs; run++ {
 				current++
 				
-				result := testModelWithUroboroCase(model, testCase, experiment.Config.TimeoutSeconds)
+				result := testModelWithUroboroCase(backend, model, testCase, experiment.Config.TimeoutSeconds)
 				result.QualityScore = evaluateQuality(result, testCase)
 				result.FormatCompliance = checkFormatCompliance(result.Output, testCase.UseCase)
 				result.TechnicalAccuracy = checkTechnicalAccuracy(result.Output)
@@ -318,7 +467,18 @@ func runUroboroExperiments(experiment *UroboroExperiment, models []string) {
 				
 				progress := float64(current) / float64(total) * 100
 				if result.Success {
-					fmt.Printf(" ✅[%.0f%%] %v", progress, result.ResponseTime.Round(time.Millisecond))
+					fmt.Printf(" ✅[%.0f%%] %v (TTFT %v) %.1ft/s", progress, result.ResponseTime.Round(time.Millisecond),
+						result.TimeToFirstToken.Round(time.Millisecond), result.TokensPerSecond)
+					if len(result.Turns) > 0 {
+						fmt.Printf(" %d turns (%s), facts kept %.0f%%", len(result.Turns),
+							formatTurnLatency(turnLatencies(result.Turns)), result.FactRetention*100)
+					}
+					if result.Structured != nil {
+						fmt.Printf(" %s", formatStructuredResult(*result.Structured))
+					}
+					if result.ToolCalls != nil {
+						fmt.Printf(" %s", formatToolCallResult(*result.ToolCalls))
+					}
 				} else {
 					fmt.Printf(" ❌[%.0f%%] %s", progress, result.Error)
 				}
@@ -328,25 +488,48 @@ func runUroboroExperiments(experiment *UroboroExperiment, models []string) {
 	}
 }
 
-func testModelWithUroboroCase(model string, testCase UroboroTestCase, timeoutSec int) UroboroTestResult {
+func testModelWithUroboroCase(backend Backend, model string, testCase UroboroTestCase, timeoutSec int) UroboroTestResult {
 	start := time.Now()
 	
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
 	defer cancel()
 	
-	cmd := exec.CommandContext(ctx, "ollama", "run", model)
-	cmd.Stdin = strings.NewReader(testCase.Prompt)
-	
-	output, err := cmd.Output()
+	var resp GenerateResponse
+	var turns []TurnResult
+	var structured *StructuredResult
+	var toolCalls *ToolCallResult
+	var err error
+	switch {
+	case testCase.Structured != nil:
+		var outcome StructuredResult
+		outcome, resp, err = runStructuredTest(ctx, backend, model, testCase.Prompt, *testCase.Structured, testCase.Options)
+		structured = &outcome
+	case testCase.ToolCalls != nil:
+		var outcome ToolCallResult
+		outcome, resp, err = runToolCallTest(ctx, backend, model, testCase.Prompt, *testCase.ToolCalls, testCase.Options)
+		toolCalls = &outcome
+	case len(testCase.Turns) > 0:
+		score := func(output string) float64 {
+			return float64(evaluateQuality(UroboroTestResult{Success: true, Output: output}, testCase))
+		}
+		turns, resp, err = runConversation(ctx, backend, model, testCase.Prompt, testCase.Turns, testCase.Options, score)
+	default:
+		resp, err = backend.Generate(ctx, GenerateRequest{Model: model, Prompt: testCase.Prompt, Options: testCase.Options})
+	}
 	responseTime := time.Since(start)
 	
 	result := UroboroTestResult{
 		Model:        model,
+		Backend:      resp.Backend,
 		UseCase:      testCase.UseCase,
 		TestName:     testCase.Name,
 		Input:        testCase.Input,
+		Options:      testCase.Options,
 		ResponseTime: responseTime,
 		Timestamp:    start,
+		Turns:        turns,
+		Structured:   structured,
+		ToolCalls:    toolCalls,
 	}
 	
 	if err != nil {
@@ -356,7 +539,15 @@ func testModelWithUroboroCase(model string, testCase UroboroTestCase, timeoutSec
 	}
 	
 	result.Success = true
-	result.Output = strings.TrimSpace(string(output))
+	result.Output = resp.Output
+	if len(turns) > 0 {
+		result.FactRetention = factRetention(turns)
+	}
+	result.GenerationMetrics = resp.Metrics
+	result.TokensPerSecond = resp.Metrics.DecodeRate()
+	result.TimeToFirstToken = resp.Timing.TimeToFirstToken
+	result.InterTokenLatency = resp.Timing.InterTokenLatency
+	result.DecodeTimeline = resp.Timing.DecodeTimeline
 	
 	return result
 }
@@ -461,11 +652,22 @@ func generateUroboroSummary(results []UroboroTestResult) UroboroSummary {
 		QualityRankings:           make(map[string][]ModelRanking),
 	}
 	
-	// Group results by use case
+	// Group results by use case; structured cases get their own ranking
 	useCaseResults := make(map[string][]UroboroTestResult)
+	structured := make(map[string][]StructuredResult)
+	toolCalls := make(map[string][]ToolCallResult)
 	for _, result := range results {
-		useCaseResults[result.UseCase] = append(useCaseResults[result.UseCase], result)
+		switch {
+		case result.Structured != nil:
+			structured[result.Model] = append(structured[result.Model], *result.Structured)
+		case result.ToolCalls != nil:
+			toolCalls[result.Model] = append(toolCalls[result.Model], *result.ToolCalls)
+		default:
+			useCaseResults[result.UseCase] = append(useCaseResults[result.UseCase], result)
+		}
 	}
+	summary.StructuredRankings = rankStructuredOutput(structured)
+	summary.ToolRankings = rankToolCalling(toolCalls)
 	
 	// Analyze each use case
 	for useCase, caseResults := range useCaseResults {
@@ -504,11 +706,21 @@ func analyzeUseCaseResults(results []UroboroTestResult) []ModelRanking {
 		totalScore := 0.0
 		successfulRuns := 0
 		totalTime := time.Duration(0)
+		totalTTFT := time.Duration(0)
+		var conversations [][]TurnResult
+		totalRetention := 0.0
 		
 		for _, res := range modelRes {
+			if len(res.Turns) > 0 {
+				conversations = append(conversations, res.Turns)
+			}
 			if res.Success {
 				successfulRuns++
+				if len(res.Turns) > 0 {
+					totalRetention += res.FactRetention
+				}
 				totalTime += res.ResponseTime
+				totalTTFT += res.TimeToFirstToken
 				// Score: Quality (50%) + Speed factor (30%) + Format compliance (20%)
 				speedScore := 5.0 - (res.ResponseTime.Seconds() / 10.0) // Penalty for slow responses
 				if speedScore < 1 {
@@ -520,7 +732,13 @@ func analyzeUseCaseResults(results []UroboroTestResult) []ModelRanking {
 					formatScore = 5.0
 				}
 				
-				testScore := float64(res.QualityScore)*0.5 + speedScore*0.3 + formatScore*0.2
+				// A refinement that drops earlier facts isn't a usable refinement
+				quality := float64(res.QualityScore)
+				if len(res.Turns) > 0 {
+					quality *= res.FactRetention
+				}
+				
+				testScore := quality*0.5 + speedScore*0.3 + formatScore*0.2
 				totalScore += testScore
 			}
 		}
@@ -528,14 +746,20 @@ func analyzeUseCaseResults(results []UroboroTestResult) []ModelRanking {
 		if successfulRuns > 0 {
 			avgScore := totalScore / float64(successfulRuns)
 			avgTime := totalTime / time.Duration(successfulRuns)
+			avgTTFT := totalTTFT / time.Duration(successfulRuns)
 			
-			reason := fmt.Sprintf("Avg quality: %.1f/5, Avg time: %v, Success: %d/%d", 
-				avgScore, avgTime.Round(time.Millisecond), successfulRuns, len(modelRes))
+			reason := fmt.Sprintf("Avg quality: %.1f/5, Avg time: %v, TTFT: %v, Success: %d/%d", 
+				avgScore, avgTime.Round(time.Millisecond), avgTTFT.Round(time.Millisecond), successfulRuns, len(modelRes))
+			if len(conversations) > 0 {
+				reason += fmt.Sprintf(", Facts kept: %.0f%%, Per-turn: %s",
+					totalRetention/float64(len(conversations))*100, formatTurnLatency(averageTurnLatency(conversations)))
+			}
 			
 			rankings = append(rankings, ModelRanking{
-				Model:  model,
-				Score:  avgScore,
-				Reason: reason,
+				Model:               model,
+				Score:               avgScore,
+				AvgTimeToFirstToken: avgTTFT,
+				Reason:              reason,
 			})
 		}
 	}
@@ -677,6 +901,9 @@ func printUroboroSummary(summary UroboroSummary) {
 		}
 	}
 	
+	printStructuredRankings(summary.StructuredRankings)
+	printToolRankings(summary.ToolRankings)
+	
 	fmt.Println("\n⚙️  Recommended uroboro Configuration:")
 	config := summary.UroboroConfig
 	fmt.Printf("  Primary Model: %s\n", config.PrimaryModel)
@@ -728,9 +955,9 @@ echo "Models configured:"
 ### Environment Setup
 
 Run the environment setup script:
-```bash
+`+"```"+`bash
 source results/uroboro_env_setup.sh
-```
+`+"```"+`
 
 Or set manually:
 %s
@@ -739,7 +966,7 @@ Or set manually:
 
 Modify your uroboro PublishService to use task-specific models:
 
-```go
+`+"```"+`go
 func NewPublishServiceWithOptimalModels() *PublishService {
     models := map[string]string{
         "capture": "%s",
@@ -754,7 +981,7 @@ func NewPublishServiceWithOptimalModels() *PublishService {
         fallbacks: %v,
     }
 }
-```
+`+"```"+`
 
 ### Performance Notes
 